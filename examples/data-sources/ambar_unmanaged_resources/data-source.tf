@@ -0,0 +1,8 @@
+# Requires ownership_tag to be set on the provider, or on the data source.
+data "ambar_unmanaged_resources" "example" {
+  resource_type = "DataSource"
+}
+
+output "unmanaged_data_sources" {
+  value = [for resource in data.ambar_unmanaged_resources.example.resources : resource.resource_id]
+}
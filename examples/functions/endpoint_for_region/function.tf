@@ -0,0 +1,3 @@
+output "ambar_endpoint" {
+  value = provider::ambar::endpoint_for_region("euw1")
+}
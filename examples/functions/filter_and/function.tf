@@ -0,0 +1,7 @@
+locals {
+  # Renders as: lookup("some") == "value" && lookup("other") > 10
+  combined_filter = provider::ambar::filter_and([
+    "lookup(\"some\") == \"value\"",
+    "lookup(\"other\") > 10",
+  ])
+}
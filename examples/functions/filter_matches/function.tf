@@ -0,0 +1,6 @@
+check "seller_filter" {
+  assert {
+    condition     = provider::ambar::filter_matches(ambar_filter.example_filter.filter_contents, { some = "value" })
+    error_message = "The example filter should match records where some is value."
+  }
+}
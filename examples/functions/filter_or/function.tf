@@ -0,0 +1,6 @@
+locals {
+  # Renders as: lookup("country") == "GB" || lookup("country") == "IE"
+  uk_or_ireland_filter = provider::ambar::filter_or([
+    for country in ["GB", "IE"] : "lookup(\"country\") == \"${country}\""
+  ])
+}
@@ -0,0 +1,8 @@
+variable "filter" {
+  type = string
+
+  validation {
+    condition     = provider::ambar::filter_validate(var.filter)
+    error_message = "The filter must be a valid Ambar Filter statement."
+  }
+}
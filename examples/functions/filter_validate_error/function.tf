@@ -0,0 +1,8 @@
+variable "filter" {
+  type = string
+
+  validation {
+    condition     = provider::ambar::filter_validate_error(var.filter) == ""
+    error_message = "The filter is not a valid Ambar Filter statement: ${provider::ambar::filter_validate_error(var.filter)}"
+  }
+}
@@ -6,6 +6,8 @@ terraform {
   }
 }
 
+# The endpoint and api_key may also be left out, and provided with the
+# AMBAR_ENDPOINT and AMBAR_ENVIRONMENT_KEY environment variables instead.
 provider "ambar" {
   endpoint = "region.api.ambar.cloud"
   api_key  = "your-key"
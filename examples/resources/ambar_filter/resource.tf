@@ -1,5 +1,33 @@
 resource "ambar_filter" "example_filter" {
   data_source_id  = ambar_data_source.example_data_source.resource_id
   description     = "My test Filter"
-  filter_contents = ""
-}
\ No newline at end of file
+  filter_contents = "lookup(\"some\") == \"value\""
+
+  # test cases are evaluated against the filter during plan, and are not sent to Ambar.
+  test_case {
+    name         = "matches some value"
+    record       = { some = "value", other = "1" }
+    expect_match = true
+  }
+}
+
+resource "ambar_filter" "example_filter_expression" {
+  data_source_id = ambar_data_source.example_data_source.resource_id
+  description    = "My structured Filter"
+  # expression can be used instead of filter_contents, and is rendered to Ambar Filter syntax by the provider.
+  expression {
+    or {
+      comparison {
+        field    = "some"
+        operator = "=="
+        value    = "value"
+      }
+      comparison {
+        field      = "other"
+        operator   = ">="
+        value      = "10"
+        value_type = "number"
+      }
+    }
+  }
+}
@@ -0,0 +1,35 @@
+resource "ambar_pipeline" "example_pipeline" {
+  source {
+    data_source_type = "postgres"
+    description      = "My Terraform Pipeline DataSource"
+    data_source_config = {
+      "hostname" : "host",
+      "hostPort" : "5432",
+      "databaseName" : "postgres",
+      "tableName" : "events",
+      "publicationName" : "example_pub",
+      "columns" : "partition,serial,country,amount",
+      "partitioningColumn" : "partition",
+      "serialColumn" : "serial",
+      "username" : "username",
+      "password" : "password"
+    }
+  }
+
+  filter {
+    description     = "Orders from the UK"
+    filter_contents = "lookup(\"country\") == \"GB\""
+  }
+
+  filter {
+    description     = "Large orders"
+    filter_contents = "lookup(\"amount\") > 1000"
+  }
+
+  destination {
+    description          = "My Terraform Pipeline DataDestination"
+    destination_endpoint = "https://1.2.3.4.com/data"
+    username             = "username"
+    password             = "password"
+  }
+}
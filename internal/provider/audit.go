@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// With audit_log_path set, the provider appends a JSON line to a local file for every Ambar API call which creates,
+// updates or deletes a resource, giving change-management processes a record of what each apply changed.
+
+// apiOperation identifies an Ambar API call.
+type apiOperation struct {
+	operation    string
+	resourceType string
+}
+
+// mutatingOperations maps the method and path of each Ambar API call which changes a resource to its operation and
+// resource type.
+var mutatingOperations = map[string]apiOperation{
+	http.MethodPost + " /source":        {"CreateDataSource", "DataSource"},
+	http.MethodPut + " /source":         {"UpdateDataSource", "DataSource"},
+	http.MethodPatch + " /source":       {"UpdateDataSourceCredentials", "DataSource"},
+	http.MethodDelete + " /source":      {"DeleteDataSource", "DataSource"},
+	http.MethodPost + " /filter":        {"CreateFilter", "Filter"},
+	http.MethodDelete + " /filter":      {"DeleteFilter", "Filter"},
+	http.MethodPost + " /destination":   {"CreateDataDestination", "DataDestination"},
+	http.MethodPut + " /destination":    {"UpdateDataDestination", "DataDestination"},
+	http.MethodPatch + " /destination":  {"UpdateDataDestinationCredentials", "DataDestination"},
+	http.MethodDelete + " /destination": {"DeleteDataDestination", "DataDestination"},
+}
+
+// mutatingOperation returns the operation and resource type of a request which changes a resource. The last element
+// of the path is used, as the endpoint may have a base path.
+func mutatingOperation(req *http.Request) (apiOperation, bool) {
+	operation, ok := mutatingOperations[req.Method+" /"+req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]]
+	return operation, ok
+}
+
+// auditEntry is a single line of the audit log.
+type auditEntry struct {
+	Timestamp    string         `json:"timestamp"`
+	Operation    string         `json:"operation"`
+	ResourceType string         `json:"resource_type"`
+	ResourceId   string         `json:"resource_id,omitempty"`
+	Request      map[string]any `json:"request,omitempty"`
+	Outcome      string         `json:"outcome"`
+	Status       int            `json:"status,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	DurationMs   int64          `json:"duration_ms"`
+}
+
+// auditLog appends entries to the audit log file. Resources are created and deleted concurrently, so writes are
+// serialized, and each entry is written with a single append so lines never interleave.
+type auditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// openAuditLog returns an audit log appending to path, checking the file can be written to.
+func openAuditLog(path string) (*auditLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return &auditLog{path: path}, nil
+}
+
+// write appends entry to the audit log.
+func (l *auditLog) write(entry auditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// auditTransport records the mutating Ambar API calls sent through it in an audit log.
+type auditTransport struct {
+	next http.RoundTripper
+	log  *auditLog
+}
+
+// newAuditTransport returns a transport recording the mutating calls sent through next in log.
+func newAuditTransport(next http.RoundTripper, log *auditLog) http.RoundTripper {
+	return &auditTransport{next: next, log: log}
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	operation, ok := mutatingOperation(req)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	entry := auditEntry{
+		Operation:    operation.operation,
+		ResourceType: operation.resourceType,
+		Outcome:      "success",
+	}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			requestBody, _ := io.ReadAll(body)
+			body.Close()
+			entry.Request = redactFields(requestBody)
+		}
+	}
+	if resourceId, ok := entry.Request["resourceId"].(string); ok {
+		entry.ResourceId = resourceId
+	}
+
+	start := time.Now()
+	entry.Timestamp = start.UTC().Format(time.RFC3339Nano)
+	resp, err := t.next.RoundTrip(req)
+	entry.DurationMs = time.Since(start).Milliseconds()
+
+	switch {
+	case err != nil:
+		entry.Outcome = "failure"
+		entry.Error = err.Error()
+	case resp.StatusCode >= 300:
+		entry.Outcome = "failure"
+		entry.Status = resp.StatusCode
+		entry.Error = resp.Status
+	default:
+		entry.Status = resp.StatusCode
+
+		// Created resources are only given an id in the response.
+		responseBody, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		var rest io.Reader = bytes.NewReader(responseBody)
+		if readErr != nil {
+			rest = io.MultiReader(rest, &errorReader{err: readErr})
+		}
+		resp.Body = io.NopCloser(rest)
+
+		var stateChange struct {
+			ResourceId string `json:"resourceId"`
+		}
+		if json.Unmarshal(responseBody, &stateChange) == nil && stateChange.ResourceId != "" {
+			entry.ResourceId = stateChange.ResourceId
+		}
+	}
+
+	if writeErr := t.log.write(entry); writeErr != nil {
+		tflog.Error(req.Context(), "Unable to write to the Ambar audit log: "+writeErr.Error(), map[string]any{"audit_log_path": t.log.path})
+	}
+
+	return resp, err
+}
+
+// redactFields returns the fields of a JSON request body, with the values of credential and filter fields replaced.
+func redactFields(body []byte) map[string]any {
+	var fields map[string]any
+	if json.Unmarshal(body, &fields) != nil {
+		return nil
+	}
+	redactValue(fields)
+	return fields
+}
+
+// redactValue replaces the values of redacted fields within value, recursing into nested objects and lists.
+func redactValue(value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		for name, field := range value {
+			redacted := false
+			for _, redactedField := range redactedFields {
+				if strings.EqualFold(name, redactedField) {
+					redacted = true
+					break
+				}
+			}
+
+			if redacted {
+				value[name] = "***"
+			} else {
+				redactValue(field)
+			}
+		}
+	case []any:
+		for _, element := range value {
+			redactValue(element)
+		}
+	}
+}
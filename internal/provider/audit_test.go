@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// readAuditLog returns the entries of the audit log at path.
+func readAuditLog(t *testing.T, path string) []auditEntry {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("audit log line %q is not valid JSON: %s", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			_, _ = w.Write([]byte(`{"resourceId": "created-id", "state": "CREATING"}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			_, _ = w.Write([]byte(`{"resourceId": "described-id", "state": "READY"}`))
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := openAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: newAuditTransport(http.DefaultTransport, log)}
+
+	send := func(method string, resourcePath string, body string) {
+		req, err := http.NewRequest(method, server.URL+resourcePath, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	send(http.MethodPost, "/source", `{"dataSourceType": "postgres", "dataSourceConfig": {"username": "admin", "password": "hunter2"}}`)
+	send(http.MethodGet, "/source", `{"resourceId": "described-id"}`)
+	send(http.MethodDelete, "/filter", `{"resourceId": "missing-id"}`)
+
+	entries := readAuditLog(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("expected only the mutating calls to be audited, got %d entries", len(entries))
+	}
+
+	created := entries[0]
+	if created.Operation != "CreateDataSource" || created.ResourceType != "DataSource" || created.ResourceId != "created-id" || created.Outcome != "success" || created.Timestamp == "" {
+		t.Errorf("unexpected create entry: %+v", created)
+	}
+	config := created.Request["dataSourceConfig"].(map[string]any)
+	if created.Request["dataSourceType"] != "postgres" || config["username"] != "***" || config["password"] != "***" {
+		t.Errorf("unexpected create request fields: %v", created.Request)
+	}
+
+	deleted := entries[1]
+	if deleted.Operation != "DeleteFilter" || deleted.ResourceId != "missing-id" || deleted.Outcome != "failure" || deleted.Status != http.StatusNotFound {
+		t.Errorf("unexpected delete entry: %+v", deleted)
+	}
+}
+
+func TestAuditLogConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := openAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := log.write(auditEntry{Operation: "DeleteFilter", ResourceType: "Filter", Request: map[string]any{"padding": strings.Repeat("x", 8192)}}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if entries := readAuditLog(t, path); len(entries) != 50 {
+		t.Errorf("expected 50 audit log entries, got %d", len(entries))
+	}
+}
+
+func TestOpenAuditLogInvalid(t *testing.T) {
+	if _, err := openAuditLog(filepath.Join(t.TempDir(), "missing", "audit.jsonl")); err == nil {
+		t.Errorf("openAuditLog expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	Ambar "github.com/ambarltd/ambar_go_client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strconv"
+	"strings"
+)
+
+// When Create times out after Ambar has accepted the request, retrying the apply would create a duplicate resource.
+// on_create_conflict lets a resource look for an existing Ambar resource matching its configuration first, and either
+// adopt it into state or refuse to create a duplicate.
+const (
+	onCreateConflictAdopt  = "adopt"
+	onCreateConflictError  = "error"
+	onCreateConflictCreate = "create"
+)
+
+// onCreateConflictAttribute returns the schema of the on_create_conflict attribute for the given resource type.
+func onCreateConflictAttribute(resourceType string) schema.StringAttribute {
+	return schema.StringAttribute{
+		MarkdownDescription: fmt.Sprintf("What to do when creating this %[1]s and Ambar already has a %[1]s with the same configuration, such as one left behind by an apply which timed out. One of `adopt`, which takes over the existing %[1]s instead of creating a new one, `error`, which fails the apply, or `create`, which always creates a new %[1]s. Credentials are not returned by Ambar, so they are not compared. Defaults to `create`.", resourceType),
+		Description:         fmt.Sprintf("What to do when creating this %[1]s and Ambar already has a %[1]s with the same configuration, such as one left behind by an apply which timed out. One of adopt, which takes over the existing %[1]s instead of creating a new one, error, which fails the apply, or create, which always creates a new %[1]s. Credentials are not returned by Ambar, so they are not compared. Defaults to create.", resourceType),
+		Optional:            true,
+		Computed:            true,
+		Default:             stringdefault.StaticString(onCreateConflictCreate),
+		Validators: []validator.String{
+			stringOneOf(onCreateConflictAdopt, onCreateConflictError, onCreateConflictCreate),
+		},
+	}
+}
+
+// findCreateConflict looks for existing resources of the given type and description which match the planned
+// configuration according to matches. It returns the id of the resource to adopt, or an empty string when a new
+// resource should be created. Errors are added to diags when the mode forbids continuing.
+func findCreateConflict(ctx context.Context, client *Ambar.APIClient, diags *diag.Diagnostics, mode types.String, resourceType string, description *string, matches func(resourceId string) (bool, error)) string {
+	if mode.ValueString() != onCreateConflictAdopt && mode.ValueString() != onCreateConflictError {
+		return ""
+	}
+
+	candidates, err := listResourceIds(ctx, client, resourceType, description)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("on_create_conflict"),
+			"Unable to check for an existing "+resourceType,
+			fmt.Sprintf("Could not list existing %s resources to check for conflicts: %s", resourceType, err.Error()),
+		)
+		return ""
+	}
+
+	var matched []string
+	for _, resourceId := range candidates {
+		ok, err := matches(resourceId)
+		if err != nil {
+			tflog.Debug(ctx, "Unable to describe "+resourceType+" "+resourceId+" while checking for conflicts: "+err.Error())
+			continue
+		}
+		if ok {
+			matched = append(matched, resourceId)
+		}
+	}
+
+	if len(matched) == 0 {
+		return ""
+	}
+
+	if mode.ValueString() == onCreateConflictError {
+		diags.AddAttributeError(
+			path.Root("on_create_conflict"),
+			resourceType+" already exists",
+			fmt.Sprintf("Ambar already has a %s with the same configuration: %s. Import it with terraform import, set "+
+				"on_create_conflict to \"adopt\" to take it over, or delete it and try again.", resourceType, strings.Join(matched, ", ")),
+		)
+		return ""
+	}
+
+	if len(matched) > 1 {
+		diags.AddAttributeError(
+			path.Root("on_create_conflict"),
+			"Multiple matching "+resourceType+" resources",
+			fmt.Sprintf("Ambar has more than one %s with the same configuration: %s. Only one can be adopted, so import "+
+				"the one to keep with terraform import, and delete the others.", resourceType, strings.Join(matched, ", ")),
+		)
+		return ""
+	}
+
+	tflog.Info(ctx, "Adopting existing "+resourceType+" "+matched[0]+" instead of creating a new one")
+	return matched[0]
+}
+
+// listResourceIds returns the ids of all resources of a type with the given description, skipping those which are
+// being deleted or have failed.
+func listResourceIds(ctx context.Context, client *Ambar.APIClient, resourceType string, description *string) ([]string, error) {
+	var resourceIds []string
+
+	var listResources Ambar.ListResourcesRequest
+	listResources.ResourceType = &resourceType
+
+	for {
+		listResourcesResponse, _, err := client.AmbarAPI.ListResources(ctx).ListResourcesRequest(listResources).Execute()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resourceTypeDetails := range listResourcesResponse.Resources {
+			if !strings.EqualFold(resourceTypeDetails.GetResourceType(), resourceType) {
+				continue
+			}
+
+			for _, details := range resourceTypeDetails.Details {
+				switch details.GetState() {
+				case "DELETING", "FAILED":
+					continue
+				}
+
+				if details.GetDescription() != stringOrEmpty(description) {
+					continue
+				}
+
+				resourceIds = append(resourceIds, details.GetResourceId())
+			}
+		}
+
+		if listResourcesResponse.NextPage == nil {
+			return resourceIds, nil
+		}
+
+		page := strconv.Itoa(int(*listResourcesResponse.NextPage))
+		listResources.Page = &page
+	}
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// stringOneOf validates that a string attribute is one of a fixed set of values.
+func stringOneOf(values ...string) validator.String {
+	return stringOneOfValidator{values: values}
+}
+
+type stringOneOfValidator struct {
+	values []string
+}
+
+func (v stringOneOfValidator) Description(ctx context.Context) string {
+	return "value must be one of: " + strings.Join(v.values, ", ")
+}
+
+func (v stringOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be one of: `" + strings.Join(v.values, "`, `") + "`"
+}
+
+func (v stringOneOfValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, value := range v.values {
+		if req.ConfigValue.ValueString() == value {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid attribute value",
+		fmt.Sprintf("%s must be one of %s, got: %q", req.Path, strings.Join(v.values, ", "), req.ConfigValue.ValueString()),
+	)
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	Ambar "github.com/ambarltd/ambar_go_client"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCreateConflictMatches(t *testing.T) {
+	description := "My DataSource"
+	createDataSource := Ambar.CreateDataSourceRequest{
+		DataSourceType: "postgres",
+		Description:    &description,
+		DataSourceConfig: map[string]string{
+			"hostname": "host",
+			"hostPort": "5432",
+			"username": "username",
+			"password": "password",
+		},
+	}
+
+	existing := &Ambar.DataSource{
+		DataSourceType:   "postgres",
+		Description:      &description,
+		DataSourceConfig: map[string]interface{}{"hostname": "host", "hostPort": "5432"},
+	}
+	if !dataSourceMatches(createDataSource, existing) {
+		t.Errorf("dataSourceMatches expected a DataSource differing only by credentials to match")
+	}
+
+	existing.DataSourceConfig["hostPort"] = "5433"
+	if dataSourceMatches(createDataSource, existing) {
+		t.Errorf("dataSourceMatches expected a DataSource with a different hostPort not to match")
+	}
+
+	createDataDestination := Ambar.CreateDataDestinationRequest{
+		DestinationEndpoint: "https://1.2.3.4.com/data",
+		FilterIds:           []string{"AMBAR-1", "AMBAR-2"},
+	}
+	if !dataDestinationMatches(createDataDestination, &Ambar.DataDestination{DestinationEndpoint: "https://1.2.3.4.com/data", FilterIds: []string{"AMBAR-2", "AMBAR-1"}}) {
+		t.Errorf("dataDestinationMatches expected Filters in a different order to match")
+	}
+	if dataDestinationMatches(createDataDestination, &Ambar.DataDestination{DestinationEndpoint: "https://1.2.3.4.com/data", Description: &description, FilterIds: []string{"AMBAR-1", "AMBAR-2"}}) {
+		t.Errorf("dataDestinationMatches expected a DataDestination with a different description not to match")
+	}
+}
+
+func TestStringOneOf(t *testing.T) {
+	tests := map[string]bool{
+		onCreateConflictAdopt: false,
+		onCreateConflictError: false,
+		"replace":             true,
+	}
+
+	for value, expectError := range tests {
+		resp := validator.StringResponse{}
+		stringOneOf(onCreateConflictAdopt, onCreateConflictError, onCreateConflictCreate).ValidateString(context.Background(), validator.StringRequest{
+			Path:        path.Root("on_create_conflict"),
+			ConfigValue: types.StringValue(value),
+		}, &resp)
+
+		if resp.Diagnostics.HasError() != expectError {
+			t.Errorf("stringOneOf(%q) reported errors %v, expected error: %t", value, resp.Diagnostics, expectError)
+		}
+	}
+}
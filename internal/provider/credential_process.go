@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// A credential_process lets teams fetch a short-lived Ambar key from their own secret broker when the provider is
+// configured, rather than keeping long-lived keys in environment variables. The command writes a JSON object to stdout:
+//
+//	{"api_key": "...", "endpoint": "euw1.api.ambar.cloud", "expiration": "2024-01-01T12:00:00Z"}
+
+// credentialProcessTimeout bounds how long the provider waits for a credential process.
+const credentialProcessTimeout = time.Minute
+
+// credentialProcessOutput is the JSON object written to stdout by a credential process. Endpoint and Expiration are
+// optional.
+type credentialProcessOutput struct {
+	ApiKey     string     `json:"api_key"`
+	Endpoint   string     `json:"endpoint"`
+	Expiration *time.Time `json:"expiration"`
+}
+
+// runCredentialProcess executes command, the program followed by its arguments, and parses the credentials it writes
+// to stdout. Anything the process writes to stderr is returned alongside, so it can be shown to the practitioner.
+func runCredentialProcess(ctx context.Context, command []string) (credentialProcessOutput, string, error) {
+	var output credentialProcessOutput
+
+	if len(command) == 0 || command[0] == "" {
+		return output, "", errors.New("no command was given")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, credentialProcessTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	tflog.Debug(ctx, "Running Ambar credential process", map[string]any{"command": command[0]})
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s", credentialProcessTimeout)
+		}
+		return output, strings.TrimSpace(stderr.String()), err
+	}
+
+	// Never include stdout in errors, as it may hold the key.
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return output, strings.TrimSpace(stderr.String()), errors.New("the output is not a JSON object with api_key, endpoint and expiration")
+	}
+
+	if output.ApiKey == "" {
+		return output, strings.TrimSpace(stderr.String()), errors.New("the output has no api_key")
+	}
+
+	if output.Expiration != nil && !output.Expiration.After(time.Now()) {
+		return output, strings.TrimSpace(stderr.String()), fmt.Errorf("the key expired at %s", output.Expiration.Format(time.RFC3339))
+	}
+
+	return output, strings.TrimSpace(stderr.String()), nil
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunCredentialProcess(t *testing.T) {
+	script := `echo "fetching key" >&2; echo '{"api_key": "short-lived", "endpoint": "euw1.api.ambar.cloud", "expiration": "2999-01-01T00:00:00Z"}'`
+
+	credentials, stderr, err := runCredentialProcess(context.Background(), []string{"/bin/sh", "-c", script})
+	if err != nil {
+		t.Fatalf("runCredentialProcess returned error: %s", err)
+	}
+	if credentials.ApiKey != "short-lived" || credentials.Endpoint != "euw1.api.ambar.cloud" || credentials.Expiration == nil || credentials.Expiration.Year() != 2999 {
+		t.Errorf("runCredentialProcess returned %+v", credentials)
+	}
+	if stderr != "fetching key" {
+		t.Errorf("runCredentialProcess returned stderr %q", stderr)
+	}
+}
+
+func TestRunCredentialProcessInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    string
+		stderr string
+	}{
+		{"failed", `echo "not logged in" >&2; exit 3`, "exit status 3", "not logged in"},
+		{"not json", `echo secret-key`, "not a JSON object", ""},
+		{"no key", `echo '{"endpoint": "euw1.api.ambar.cloud"}'`, "no api_key", ""},
+		{"expired", `echo '{"api_key": "old", "expiration": "2000-01-01T00:00:00Z"}'`, "expired", ""},
+	}
+
+	for _, test := range tests {
+		_, stderr, err := runCredentialProcess(context.Background(), []string{"/bin/sh", "-c", test.script})
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: runCredentialProcess returned error %v, expected %q", test.name, err, test.err)
+		}
+		if err != nil && strings.Contains(err.Error(), "secret-key") {
+			t.Errorf("%s: runCredentialProcess included stdout in error %q", test.name, err)
+		}
+		if stderr != test.stderr {
+			t.Errorf("%s: runCredentialProcess returned stderr %q, expected %q", test.name, stderr, test.stderr)
+		}
+	}
+
+	if _, _, err := runCredentialProcess(context.Background(), nil); err == nil {
+		t.Errorf("runCredentialProcess expected an error without a command")
+	}
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Teams managing several Ambar environments can keep the endpoint and key of each in a shared credentials file, and
+// select one with the provider profile attribute or the AMBAR_PROFILE environment variable. The file holds one section
+// per profile, written as either INI or TOML:
+//
+//	[payments-prod]
+//	endpoint = "euw1.api.ambar.cloud"
+//	api_key  = "..."
+
+// credentialsProfile holds the settings of a single profile in the shared credentials file.
+type credentialsProfile struct {
+	Endpoint string
+	ApiKey   string
+}
+
+// sharedCredentialsFile returns the location of the shared credentials file, ~/.ambar/credentials.
+func sharedCredentialsFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ambar", "credentials"), nil
+}
+
+// loadCredentialsProfile reads the named profile from the shared credentials file at filename.
+func loadCredentialsProfile(filename string, profile string) (credentialsProfile, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return credentialsProfile{}, err
+	}
+	defer file.Close()
+
+	profiles, err := parseCredentials(file)
+	if err != nil {
+		return credentialsProfile{}, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	settings, ok := profiles[profile]
+	if !ok {
+		return credentialsProfile{}, fmt.Errorf("%s has no profile named %q", filename, profile)
+	}
+	return settings, nil
+}
+
+// parseCredentials parses the profiles of a shared credentials file. Both INI, with bare values and ; or # comments,
+// and TOML, with quoted values, are accepted. Settings other than endpoint and api_key are ignored, so the file can be
+// shared with other Ambar tooling.
+func parseCredentials(reader io.Reader) (map[string]credentialsProfile, error) {
+	profiles := map[string]credentialsProfile{}
+	profile := ""
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated profile name", lineNumber)
+			}
+			profile = strings.Trim(strings.TrimSpace(line[1:end]), `"'`)
+			if _, ok := profiles[profile]; !ok {
+				profiles[profile] = credentialsProfile{}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a key = value setting", lineNumber)
+		}
+		if profile == "" {
+			return nil, fmt.Errorf("line %d: setting outside of a [profile] section", lineNumber)
+		}
+
+		value, err := parseCredentialsValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		settings := profiles[profile]
+		switch strings.TrimSpace(key) {
+		case "endpoint":
+			settings.Endpoint = value
+		case "api_key":
+			settings.ApiKey = value
+		}
+		profiles[profile] = settings
+	}
+
+	return profiles, scanner.Err()
+}
+
+// parseCredentialsValue returns the value of a setting, removing TOML quotes or a trailing comment.
+func parseCredentialsValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		for end := 1; end < len(value); end++ {
+			switch value[end] {
+			case '\\':
+				end++
+			case '"':
+				return strconv.Unquote(value[:end+1])
+			}
+		}
+		return "", fmt.Errorf("unterminated string %s", value)
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		return value[1 : end+1], nil
+	}
+
+	for _, comment := range []string{" #", " ;"} {
+		if index := strings.Index(value, comment); index >= 0 {
+			value = value[:index]
+		}
+	}
+	return strings.TrimSpace(value), nil
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCredentials(t *testing.T) {
+	credentials := `
+# Shared by the Ambar tooling
+[payments-prod]
+endpoint = euw1.api.ambar.cloud ; Ireland
+api_key  = ini-key
+
+["payments-staging"]
+endpoint = "use1.api.ambar.cloud" # Virginia
+api_key  = "toml-\"key\""
+region   = 'ignored'
+
+[empty]
+`
+
+	profiles, err := parseCredentials(strings.NewReader(credentials))
+	if err != nil {
+		t.Fatalf("parseCredentials returned error: %s", err)
+	}
+
+	expected := map[string]credentialsProfile{
+		"payments-prod":    {Endpoint: "euw1.api.ambar.cloud", ApiKey: "ini-key"},
+		"payments-staging": {Endpoint: "use1.api.ambar.cloud", ApiKey: `toml-"key"`},
+		"empty":            {},
+	}
+	if len(profiles) != len(expected) {
+		t.Errorf("parseCredentials returned %d profiles, expected %d", len(profiles), len(expected))
+	}
+	for name, settings := range expected {
+		if profiles[name] != settings {
+			t.Errorf("profile %s: got %+v, expected %+v", name, profiles[name], settings)
+		}
+	}
+}
+
+func TestParseCredentialsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"outside of a profile": "endpoint = euw1.api.ambar.cloud",
+		"unterminated profile": "[payments-prod",
+		"not a setting":        "[payments-prod]\nendpoint",
+		"unterminated string":  "[payments-prod]\napi_key = \"key",
+	}
+
+	for name, credentials := range tests {
+		if _, err := parseCredentials(strings.NewReader(credentials)); err == nil {
+			t.Errorf("%s: expected parseCredentials to return an error", name)
+		}
+	}
+}
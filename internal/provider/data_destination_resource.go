@@ -5,25 +5,31 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	Ambar "github.com/ambarltd/ambar_go_client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &DataDestinationResource{}
 var _ resource.ResourceWithImportState = &DataDestinationResource{}
+var _ resource.ResourceWithModifyPlan = &DataDestinationResource{}
+var _ resource.ResourceWithUpgradeState = &DataDestinationResource{}
 
 func NewDataDestinationResource() resource.Resource {
 	return &DataDestinationResource{}
@@ -31,16 +37,33 @@ func NewDataDestinationResource() resource.Resource {
 
 // DataDestinationResource defines the resource implementation.
 type DataDestinationResource struct {
-	client *Ambar.APIClient
+	client             *Ambar.APIClient
+	ownershipTag       string
+	readOnly           bool
+	defaultDescription descriptionDecoration
+}
+
+// dataDestinationResourceModelV0 describes the data model of schema version 0.
+type dataDestinationResourceModelV0 struct {
+	FilterIds           types.List   `tfsdk:"filter_ids"`
+	Description         types.String `tfsdk:"description"`
+	DestinationEndpoint types.String `tfsdk:"destination_endpoint"`
+	Username            types.String `tfsdk:"username"`
+	Password            types.String `tfsdk:"password"`
+	State               types.String `tfsdk:"state"`
+	ResourceId          types.String `tfsdk:"resource_id"`
 }
 
 // DataDestinationResourceModel describes the resource data model.
 type dataDestinationResourceModel struct {
-	FilterIds           types.List   `tfsdk:"filter_ids"`
+	FilterIds           types.Set    `tfsdk:"filter_ids"`
 	Description         types.String `tfsdk:"description"`
 	DestinationEndpoint types.String `tfsdk:"destination_endpoint"`
 	Username            types.String `tfsdk:"username"`
 	Password            types.String `tfsdk:"password"`
+	PreventReplay       types.Bool   `tfsdk:"prevent_replay"`
+	DeletionProtection  types.Bool   `tfsdk:"deletion_protection"`
+	OnCreateConflict    types.String `tfsdk:"on_create_conflict"`
 	State               types.String `tfsdk:"state"`
 	ResourceId          types.String `tfsdk:"resource_id"`
 }
@@ -55,37 +78,14 @@ func (r *DataDestinationResource) Schema(ctx context.Context, req resource.Schem
 		MarkdownDescription: "Ambar DataDestination resource. Represents details about a Destination HTTP server you have configured to receive filtered record sequences from Ambar.",
 		Description:         "Ambar DataDestination resource. Represents details about a Destination HTTP server you have configured to receive filtered record sequences from Ambar.",
 
+		Version: 1,
+
 		Attributes: map[string]schema.Attribute{
-			"filter_ids": schema.ListAttribute{
+			"filter_ids": schema.SetAttribute{
 				ElementType:         types.StringType,
-				MarkdownDescription: "A List of Ambar resource ids belonging to Ambar Filter resources which should be used with this DataDestination. These control what DataSources and applied filters will be delivered to your destination. Note that a DataSource can only be used once per DataDestination.",
-				Description:         "A List of Ambar resource ids belonging to Ambar Filter resources which should be used with this DataDestination. These control what DataSources and applied filters will be delivered to your destination. Note that a DataSource can only be used once per DataDestination.",
+				MarkdownDescription: "A Set of Ambar resource ids belonging to Ambar Filter resources which should be used with this DataDestination. These control what DataSources and applied filters will be delivered to your destination. Note that a DataSource can only be used once per DataDestination.",
+				Description:         "A Set of Ambar resource ids belonging to Ambar Filter resources which should be used with this DataDestination. These control what DataSources and applied filters will be delivered to your destination. Note that a DataSource can only be used once per DataDestination.",
 				Optional:            true,
-				PlanModifiers: []planmodifier.List{
-					listplanmodifier.RequiresReplaceIf(
-						func(ctx context.Context, req planmodifier.ListRequest, resp *listplanmodifier.RequiresReplaceIfFuncResponse) {
-							var current dataDestinationResourceModel
-							// Read Terraform prior state data into the model
-							resp.Diagnostics.Append(req.State.Get(ctx, &current)...)
-
-							if resp.Diagnostics.HasError() {
-								return
-							}
-
-							var plan dataDestinationResourceModel
-							// Read Terraform plan data into the model
-							resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
-
-							if resp.Diagnostics.HasError() {
-								return
-							}
-
-							// Check if list size has changed, which requires replacement
-							resp.RequiresReplace = len(current.FilterIds.Elements()) != len(plan.FilterIds.Elements())
-						},
-						"DataDestination filters can only be replaced with a filter for the same DataSource, and does not reset message transport. Adding Filters which refer to a new DataSource, or removing Filters will require replacement of the DataDestination.",
-						"DataDestination filters can only be **replaced** with a filter for the same DataSource, and does not reset message transport. Adding Filters which refer to a new DataSource, or removing Filters will require replacement of the DataDestination."),
-				},
 			},
 			"description": schema.StringAttribute{
 				MarkdownDescription: "A user friendly description of this DataDestination. Use the description filed to help augment information about this DataDestination which may may not be apparent from describing the resource, such as details about the filtered record sequences being sent.",
@@ -112,6 +112,21 @@ func (r *DataDestinationResource) Schema(ctx context.Context, req resource.Schem
 				Required:            true,
 				Sensitive:           true,
 			},
+			"prevent_replay": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, plans which would replace this DataDestination fail instead. Replacing a DataDestination resets message transport, replaying every record from the beginning. Defaults to `false`.",
+				Description:         "When true, plans which would replace this DataDestination fail instead. Replacing a DataDestination resets message transport, replaying every record from the beginning. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"deletion_protection": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, plans which would destroy or replace this DataDestination fail instead. Set to `false` and apply before destroying the DataDestination. Defaults to `false`.",
+				Description:         "When true, plans which would destroy or replace this DataDestination fail instead. Set to false and apply before destroying the DataDestination. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"on_create_conflict": onCreateConflictAttribute("DataDestination"),
 			"state": schema.StringAttribute{
 				MarkdownDescription: "The current state of the Ambar resource.",
 				Description:         "The current state of the Ambar resource.",
@@ -129,27 +144,380 @@ func (r *DataDestinationResource) Schema(ctx context.Context, req resource.Schem
 	}
 }
 
+// filterIdsRequireReplace checks whether a change of filter_ids can be done in place. Ambar only allows a Filter to
+// be swapped for another Filter on the same DataSource, any other change requires the DataDestination be replaced.
+// Filters which are not created yet, such as a Filter replaced to change its filter_contents, are planned in place
+// with a warning, leaving Ambar to reject a swap across DataSources during apply. Filters which exist but can't be
+// described are planned as replacements, so prevent_replay and deletion_protection are still checked.
+//
+// This is checked in ModifyPlan rather than by a plan modifier of filter_ids, as Filters can only be described once
+// the resource has been configured. Filters are described through filters, which caches them for the whole plan.
+func (r *DataDestinationResource) filterIdsRequireReplace(ctx context.Context, filters map[string]describedFilter, stateValue types.Set, planValue types.Set) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if planValue.IsUnknown() {
+		diags.Append(unknownFiltersWarning())
+		return false, diags
+	}
+
+	// Adding or removing Filters always changes the DataSources being delivered.
+	if len(stateValue.Elements()) != len(planValue.Elements()) {
+		tflog.Info(ctx, "Detected Filters being added or removed, which requires replace")
+		return true, diags
+	}
+
+	var current, plan []types.String
+	diags.Append(stateValue.ElementsAs(ctx, &current, false)...)
+	diags.Append(planValue.ElementsAs(ctx, &plan, false)...)
+
+	if diags.HasError() {
+		return false, diags
+	}
+
+	planned := make(map[string]bool)
+	for _, filterId := range plan {
+		// Filters created in the same apply can't be resolved to their DataSource until they exist.
+		if filterId.IsUnknown() {
+			diags.Append(unknownFiltersWarning())
+			return false, diags
+		}
+		planned[filterId.ValueString()] = true
+	}
+
+	// Count the DataSources of the Filters being removed, then take away those of the Filters being added. Every
+	// DataSource must balance out for the change to be a like for like swap.
+	swapped := make(map[string]int)
+	for _, filterId := range current {
+		if planned[filterId.ValueString()] {
+			delete(planned, filterId.ValueString())
+			continue
+		}
+
+		dataSourceId, err := r.describeFilterDataSource(ctx, filters, filterId.ValueString())
+		if err != nil {
+			diags.Append(unresolvedFilterWarning(filterId.ValueString(), err))
+			return true, diags
+		}
+		swapped[dataSourceId]++
+	}
+
+	for filterId := range planned {
+		dataSourceId, err := r.describeFilterDataSource(ctx, filters, filterId)
+		if err != nil {
+			diags.Append(unresolvedFilterWarning(filterId, err))
+			return true, diags
+		}
+		swapped[dataSourceId]--
+	}
+
+	for dataSourceId, count := range swapped {
+		if count != 0 {
+			tflog.Info(ctx, "Detected Filters being swapped across DataSources, which requires replace. DataSource: "+dataSourceId)
+			return true, diags
+		}
+	}
+
+	return false, diags
+}
+
+// unresolvedFilterWarning explains why a change of filter_ids is planned as a replacement when one of the Filters
+// can't be described.
+func unresolvedFilterWarning(filterId string, err error) diag.Diagnostic {
+	return diag.NewAttributeWarningDiagnostic(
+		path.Root("filter_ids"),
+		"Unable to check DataDestination Filters",
+		"Could not describe Filter "+filterId+" to find its DataSource, so the change of filter_ids is planned as a replacement of the DataDestination: "+err.Error(),
+	)
+}
+
+// unknownFiltersWarning explains that a change of filter_ids including Filters which are not created yet is planned in
+// place without checking their DataSources.
+func unknownFiltersWarning() diag.Diagnostic {
+	return diag.NewAttributeWarningDiagnostic(
+		path.Root("filter_ids"),
+		"DataDestination Filters not known until apply",
+		"The filter_ids include Filters which are not created yet, so the provider can not check they use the same DataSources as the Filters they replace. "+
+			"The DataDestination is planned to be updated in place, keeping its delivery position. Ambar rejects the update during apply if a Filter is swapped for one on a different DataSource.",
+	)
+}
+
+// describedFilter is the result of describing a Filter, kept for the rest of the plan.
+type describedFilter struct {
+	filter       *Ambar.Filter
+	httpResponse *http.Response
+	err          error
+}
+
+// describeFilter describes a Filter. Results, including errors, are kept in filters, so each Filter is only described
+// once per plan.
+func (r *DataDestinationResource) describeFilter(ctx context.Context, filters map[string]describedFilter, filterId string) describedFilter {
+	if described, ok := filters[filterId]; ok {
+		return described
+	}
+
+	var described describedFilter
+	if r.client == nil {
+		described.err = errors.New("the provider has not been configured")
+	} else {
+		var describeFilter Ambar.DescribeResourceRequest
+		describeFilter.ResourceId = filterId
+
+		described.filter, described.httpResponse, described.err = r.client.AmbarAPI.DescribeFilter(ctx).DescribeResourceRequest(describeFilter).Execute()
+	}
+
+	filters[filterId] = described
+	return described
+}
+
+// describeFilterDataSource returns the id of the DataSource a Filter applies to.
+func (r *DataDestinationResource) describeFilterDataSource(ctx context.Context, filters map[string]describedFilter, filterId string) (string, error) {
+	described := r.describeFilter(ctx, filters, filterId)
+	if described.err != nil {
+		tflog.Debug(ctx, "Unable to describe Filter "+filterId+" to find its DataSource: "+described.err.Error())
+		return "", described.err
+	}
+
+	return described.filter.DataSourceId, nil
+}
+
+func (r *DataDestinationResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 stored filter_ids as a list, which made reordering Filters show up as a change.
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"filter_ids": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+					"description": schema.StringAttribute{
+						Optional: true,
+					},
+					"destination_endpoint": schema.StringAttribute{
+						Required: true,
+					},
+					"username": schema.StringAttribute{
+						Required:  true,
+						Sensitive: true,
+					},
+					"password": schema.StringAttribute{
+						Required:  true,
+						Sensitive: true,
+					},
+					"state": schema.StringAttribute{
+						Computed: true,
+					},
+					"resource_id": schema.StringAttribute{
+						Computed: true,
+					},
+				},
+			},
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior dataDestinationResourceModelV0
+
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				filterIds := types.SetNull(types.StringType)
+				if !prior.FilterIds.IsNull() {
+					var diags diag.Diagnostics
+					filterIds, diags = types.SetValue(types.StringType, prior.FilterIds.Elements())
+					resp.Diagnostics.Append(diags...)
+				}
+
+				upgraded := dataDestinationResourceModel{
+					FilterIds:           filterIds,
+					Description:         prior.Description,
+					DestinationEndpoint: prior.DestinationEndpoint,
+					Username:            prior.Username,
+					Password:            prior.Password,
+					PreventReplay:       types.BoolValue(false),
+					DeletionProtection:  types.BoolValue(false),
+					OnCreateConflict:    types.StringValue(onCreateConflictCreate),
+					State:               prior.State,
+					ResourceId:          prior.ResourceId,
+				}
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, upgraded)...)
+			},
+		},
+	}
+}
+
+// dataDestinationMatches reports whether an existing DataDestination has the endpoint, description and Filters being
+// created. Credentials are not returned by Ambar, so they are not compared.
+func dataDestinationMatches(createDataDestination Ambar.CreateDataDestinationRequest, describeResourceResponse *Ambar.DataDestination) bool {
+	if describeResourceResponse.DestinationEndpoint != createDataDestination.DestinationEndpoint ||
+		stringOrEmpty(describeResourceResponse.Description) != stringOrEmpty(createDataDestination.Description) ||
+		len(describeResourceResponse.FilterIds) != len(createDataDestination.FilterIds) {
+		return false
+	}
+
+	for _, filterId := range createDataDestination.FilterIds {
+		if !slices.Contains(describeResourceResponse.FilterIds, filterId) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (r *DataDestinationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*Ambar.APIClient)
+	providerData, ok := req.ProviderData.(*ambarProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *Ambar.APIClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ambarProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = providerData.Client
+	r.ownershipTag = providerData.OwnershipTag
+	r.readOnly = providerData.ReadOnly
+	r.defaultDescription = providerData.DefaultDescription
+}
+
+func (r *DataDestinationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Only the guards need checking when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		var current dataDestinationResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &current)...)
+
+		if !resp.Diagnostics.HasError() {
+			checkResourceGuards(&resp.Diagnostics, "DataDestination", current.ResourceId.ValueString(), nil, current.PreventReplay.ValueBool(), current.DeletionProtection.ValueBool())
+		}
+		return
+	}
+
+	// DataDestinations which are not changing have already been checked, and their Filters may be failing for reasons
+	// which should not block unrelated changes.
+	if !req.State.Raw.IsNull() && req.Plan.Raw.Equal(req.State.Raw) {
+		return
+	}
+
+	var plan dataDestinationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Each Filter is described once, and used for every check below.
+	filters := make(map[string]describedFilter)
+
+	if !req.State.Raw.IsNull() {
+		var current dataDestinationResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &current)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var filterIdsReplaced bool
+		if !plan.FilterIds.Equal(current.FilterIds) {
+			var diags diag.Diagnostics
+			filterIdsReplaced, diags = r.filterIdsRequireReplace(ctx, filters, current.FilterIds, plan.FilterIds)
+			resp.Diagnostics.Append(diags...)
+
+			if filterIdsReplaced {
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("filter_ids"))
+			}
+		}
+
+		r.checkReplacement(ctx, &resp.Diagnostics, filters, current, plan, filterIdsReplaced)
+	}
+
+	// Filters can only be checked once the provider has been configured.
+	if r.client == nil || plan.FilterIds.IsUnknown() {
+		return
+	}
+
+	// Check each Filter which is already known. Filters created in the same apply can only be checked later.
+	for _, element := range plan.FilterIds.Elements() {
+		filterId, ok := element.(types.String)
+		if !ok || filterId.IsUnknown() || filterId.IsNull() {
+			continue
+		}
+
+		described := r.describeFilter(ctx, filters, filterId.ValueString())
+		var filterState string
+		if described.filter != nil {
+			filterState = described.filter.State
+		}
+
+		checkDependency(ctx, &resp.Diagnostics, path.Root("filter_ids").AtSetValue(filterId), "Filter", filterId.ValueString(), filterState, described.httpResponse, described.err)
+	}
+}
+
+// checkReplacement warns when the plan replaces the DataDestination. A new DataDestination has no delivery position,
+// so Ambar delivers every historical record of its DataSources to the endpoint again, which is easy to miss in an
+// ordinary -/+ plan. Replacements are blocked instead when prevent_replay or deletion_protection are enabled.
+func (r *DataDestinationResource) checkReplacement(ctx context.Context, diags *diag.Diagnostics, filters map[string]describedFilter, current dataDestinationResourceModel, plan dataDestinationResourceModel, filterIdsReplaced bool) {
+	var replacedBy []string
+	if !plan.Description.Equal(current.Description) {
+		replacedBy = append(replacedBy, "description")
+	}
+	if filterIdsReplaced {
+		replacedBy = append(replacedBy, "filter_ids")
+	}
+
+	if len(replacedBy) == 0 {
+		return
+	}
+
+	// Guards enabled in either the state or the plan apply, so turning one off has to be applied on its own first.
+	if !checkResourceGuards(diags, "DataDestination", current.ResourceId.ValueString(), replacedBy,
+		current.PreventReplay.ValueBool() || plan.PreventReplay.ValueBool(),
+		current.DeletionProtection.ValueBool() || plan.DeletionProtection.ValueBool()) {
+		return
+	}
+
+	var currentFilterIds []string
+	_ = current.FilterIds.ElementsAs(ctx, &currentFilterIds, false)
+
+	var dataSourceIds []string
+	for _, filterId := range currentFilterIds {
+		if dataSourceId, err := r.describeFilterDataSource(ctx, filters, filterId); err == nil && !slices.Contains(dataSourceIds, dataSourceId) {
+			dataSourceIds = append(dataSourceIds, dataSourceId)
+		}
+	}
+	slices.Sort(dataSourceIds)
+
+	replayed := "its DataSources"
+	if len(dataSourceIds) > 0 {
+		replayed = "the DataSources " + strings.Join(dataSourceIds, ", ")
+	}
+
+	endpoint := plan.DestinationEndpoint
+	if endpoint.IsUnknown() {
+		endpoint = current.DestinationEndpoint
+	}
+
+	diags.AddWarning(
+		"DataDestination replacement will replay records",
+		fmt.Sprintf("This change replaces the DataDestination %s, which resets its message transport and loses its delivery position. "+
+			"Every historical record from %s will be delivered to %s again, starting from the beginning. "+
+			"Make sure the endpoint can handle the replay, or only swap Filters for others on the same DataSource to update the DataDestination in place.",
+			current.ResourceId.ValueString(), replayed, endpoint.ValueString()),
+	)
 }
 
 func (r *DataDestinationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "create", "DataDestination") {
+		return
+	}
+
 	// Retrieve values from plan
 	var plan dataDestinationResourceModel
 	diags := req.Plan.Get(ctx, &plan)
@@ -166,35 +534,68 @@ func (r *DataDestinationResource) Create(ctx context.Context, req resource.Creat
 
 	createDataDestination.FilterIds = elements
 
-	createDataDestination.Description = plan.Description.ValueStringPointer()
+	decoration := r.defaultDescription.resolve("ambar_data_destination")
+	createDataDestination.Description = withOwnershipTag(decoration.apply(plan.Description.ValueStringPointer()), r.ownershipTag)
 	createDataDestination.Username = plan.Username.ValueString()
 	createDataDestination.Password = plan.Password.ValueString()
 	createDataDestination.DestinationEndpoint = plan.DestinationEndpoint.ValueString()
 
-	// Create the API call and execute it
-	createResourceResponse, httpResponse, err := r.client.AmbarAPI.CreateDataDestination(ctx).CreateDataDestinationRequest(createDataDestination).Execute()
-	if err != nil || createResourceResponse == nil || httpResponse == nil {
-		tflog.Debug(ctx, "StatusCode: "+httpResponse.Status)
-		httpBody, _ := io.ReadAll(httpResponse.Body)
-		errString := string(httpBody)
-		tflog.Debug(ctx, errString)
-		resp.Diagnostics.AddError(
-			"Error creating DataDestination",
-			"Could not create DataDestination: "+AmbarApiErrorToTerraformErrorString(errString),
-		)
+	// Look for a DataDestination left behind by an earlier attempt before creating a new one, remembering the state of each
+	// candidate so an adopted DataDestination can be saved to state straight away
+	candidateStates := map[string]string{}
+	adoptedId := findCreateConflict(ctx, r.client, &resp.Diagnostics, plan.OnCreateConflict, "DataDestination", createDataDestination.Description, func(resourceId string) (bool, error) {
+		var describeDataDestination Ambar.DescribeResourceRequest
+		describeDataDestination.ResourceId = resourceId
+
+		describeResourceResponse, _, err := r.client.AmbarAPI.DescribeDataDestination(ctx).DescribeResourceRequest(describeDataDestination).Execute()
+		if err != nil {
+			return false, err
+		}
+		candidateStates[resourceId] = describeResourceResponse.State
+		return dataDestinationMatches(createDataDestination, describeResourceResponse), nil
+	})
+
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	plan.ResourceId = types.StringValue(createResourceResponse.ResourceId)
-	plan.State = types.StringValue(createResourceResponse.State)
+	if adoptedId != "" {
+		plan.ResourceId = types.StringValue(adoptedId)
+		plan.State = types.StringValue(candidateStates[adoptedId])
+
+		// Save the adopted DataDestination to state, so it is not adopted again if waiting for it fails
+		diags = resp.State.Set(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+	} else {
+		// Create the API call and execute it
+		createResourceResponse, httpResponse, err := r.client.AmbarAPI.CreateDataDestination(ctx).CreateDataDestinationRequest(createDataDestination).Execute()
+		if err != nil || createResourceResponse == nil || httpResponse == nil {
+			tflog.Debug(ctx, "StatusCode: "+httpResponse.Status)
+			httpBody, _ := io.ReadAll(httpResponse.Body)
+			errString := string(httpBody)
+			tflog.Debug(ctx, errString)
+			resp.Diagnostics.AddError(
+				"Error creating DataDestination",
+				"Could not create DataDestination: "+AmbarApiErrorToTerraformErrorString(errString),
+			)
+			return
+		}
 
-	diags = resp.State.Set(ctx, plan)
-	resp.Diagnostics.Append(diags...)
+		plan.ResourceId = types.StringValue(createResourceResponse.ResourceId)
+		plan.State = types.StringValue(createResourceResponse.State)
+
+		diags = resp.State.Set(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	// Remember the decoration used, so Read can remove it even if the provider configuration changes
+	resp.Diagnostics.Append(decoration.save(ctx, resp.Private)...)
 
 	var describeDataDestination Ambar.DescribeResourceRequest
-	describeDataDestination.ResourceId = createResourceResponse.ResourceId
+	describeDataDestination.ResourceId = plan.ResourceId.ValueString()
 
 	var describeResourceResponse *Ambar.DataDestination
+	var err error
 
 	for {
 		time.Sleep(10 * time.Second)
@@ -202,6 +603,10 @@ func (r *DataDestinationResource) Create(ctx context.Context, req resource.Creat
 		describeResourceResponse, _, err = r.client.AmbarAPI.DescribeDataDestination(ctx).DescribeResourceRequest(describeDataDestination).Execute()
 		if err != nil {
 			tflog.Debug(ctx, "Got error while waiting for resource to become ready: "+err.Error())
+			resp.Diagnostics.AddError(
+				"Error waiting for DataDestination",
+				"Could not describe DataDestination "+describeDataDestination.ResourceId+" while waiting for it to become READY: "+err.Error(),
+			)
 			return
 		}
 
@@ -211,7 +616,6 @@ func (r *DataDestinationResource) Create(ctx context.Context, req resource.Creat
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	plan.ResourceId = types.StringValue(createResourceResponse.ResourceId)
 	plan.State = types.StringValue(describeResourceResponse.State)
 
 	// Set state to fully populated data
@@ -259,15 +663,33 @@ func (r *DataDestinationResource) Read(ctx context.Context, req resource.ReadReq
 
 	data.State = types.StringValue(describeResourceResponse.State)
 	data.DestinationEndpoint = types.StringValue(describeResourceResponse.DestinationEndpoint)
-	data.Description = types.StringPointerValue(describeResourceResponse.Description)
+	decoration, diags := loadDescriptionDecoration(ctx, req.Private, r.defaultDescription.resolve("ambar_data_destination"))
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(decoration.save(ctx, resp.Private)...)
+	data.Description = types.StringPointerValue(decoration.strip(withoutOwnershipTag(describeResourceResponse.Description)))
+
+	data.FilterIds, _ = types.SetValueFrom(ctx, types.StringType, describeResourceResponse.FilterIds)
 
-	data.FilterIds, _ = types.ListValueFrom(ctx, types.StringType, describeResourceResponse.FilterIds)
+	// The guards only exist in Terraform, so imported or older state will not have them set yet.
+	if data.PreventReplay.IsNull() {
+		data.PreventReplay = types.BoolValue(false)
+	}
+	if data.DeletionProtection.IsNull() {
+		data.DeletionProtection = types.BoolValue(false)
+	}
+	if data.OnCreateConflict.IsNull() {
+		data.OnCreateConflict = types.StringValue(onCreateConflictCreate)
+	}
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *DataDestinationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "update", "DataDestination") {
+		return
+	}
+
 	// Ambar supports resource updates for credential rotations, and destinationEndpoints. Instead, all attributes
 	// should include the PlanModifier indicating replacement is required on changes. RequiresReplace()
 	var plan dataDestinationResourceModel
@@ -311,6 +733,13 @@ func (r *DataDestinationResource) Update(ctx context.Context, req resource.Updat
 	// Check if either the endpoint or FilterIds have changed
 	var updatedNonCredentials = plan.DestinationEndpoint.ValueString() != current.DestinationEndpoint.ValueString() || filterIdsChanged
 
+	// Changes to prevent_replay or deletion_protection alone only need to be saved to state.
+	if !updatedCredentials && !updatedNonCredentials {
+		plan.State = current.State
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		return
+	}
+
 	var updateResourceResponse Ambar.ResourceStateChangeResponse
 
 	if updatedCredentials {
@@ -361,6 +790,10 @@ func (r *DataDestinationResource) Update(ctx context.Context, req resource.Updat
 }
 
 func (r *DataDestinationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "delete", "DataDestination") {
+		return
+	}
+
 	var data dataDestinationResourceModel
 
 	// Read Terraform prior state data into the model
@@ -1,8 +1,21 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 const (
@@ -34,3 +47,227 @@ func TestAmbarDataDestinationResource(t *testing.T) {
 		},
 	})
 }
+
+// testFilterServer serves Filters applying to the given DataSources, and counts how often each Filter is described.
+func testFilterServer(t *testing.T, filterDataSources map[string]string) (*httptest.Server, map[string]int) {
+	var mu sync.Mutex
+	described := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var describe struct {
+			ResourceId string `json:"resourceId"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&describe)
+
+		mu.Lock()
+		described[describe.ResourceId]++
+		mu.Unlock()
+
+		dataSourceId, ok := filterDataSources[describe.ResourceId]
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"internal error"}`))
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"createdAt":                   "2025-01-01T00:00:00Z",
+			"dataDestinationsUsingFilter": []string{},
+			"dataSourceId":                dataSourceId,
+			"resourceId":                  describe.ResourceId,
+			"filterContents":              "",
+			"state":                       "READY",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, described
+}
+
+func TestFilterIdsRequireReplace(t *testing.T) {
+	filterIds := func(ids ...string) types.Set {
+		elements := make([]attr.Value, 0, len(ids))
+		for _, id := range ids {
+			elements = append(elements, types.StringValue(id))
+		}
+		return types.SetValueMust(types.StringType, elements)
+	}
+
+	tests := []struct {
+		name     string
+		state    types.Set
+		plan     types.Set
+		expected bool
+		warning  bool
+	}{
+		{"swap on the same DataSource", filterIds("filter-a1"), filterIds("filter-a2"), false, false},
+		{"swap across DataSources", filterIds("filter-a1"), filterIds("filter-b1"), true, false},
+		{"Filter added", filterIds("filter-a1"), filterIds("filter-a1", "filter-b1"), true, false},
+		{"unknown filter_ids", filterIds("filter-a1"), types.SetUnknown(types.StringType), false, true},
+		{"unknown Filter", filterIds("filter-a1"), types.SetValueMust(types.StringType, []attr.Value{types.StringUnknown()}), false, true},
+		{"Filter can't be described", filterIds("filter-a1"), filterIds("filter-missing"), true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, _ := testFilterServer(t, map[string]string{
+				"filter-a1": "source-a",
+				"filter-a2": "source-a",
+				"filter-b1": "source-b",
+			})
+			r := &DataDestinationResource{client: testAmbarClient(server)}
+
+			requiresReplace, diags := r.filterIdsRequireReplace(context.Background(), make(map[string]describedFilter), test.state, test.plan)
+			if diags.HasError() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+			if requiresReplace != test.expected {
+				t.Errorf("expected requires replace %t, got %t", test.expected, requiresReplace)
+			}
+			if warned := diags.WarningsCount() > 0; warned != test.warning {
+				t.Errorf("expected warning %t, got: %v", test.warning, diags)
+			}
+		})
+	}
+}
+
+func TestFilterIdsRequireReplaceDescribesFiltersOnce(t *testing.T) {
+	server, described := testFilterServer(t, map[string]string{
+		"filter-a1": "source-a",
+		"filter-b1": "source-b",
+	})
+	r := &DataDestinationResource{client: testAmbarClient(server)}
+
+	state := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("filter-a1")})
+	plan := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("filter-b1")})
+
+	filters := make(map[string]describedFilter)
+	for range 2 {
+		if requiresReplace, _ := r.filterIdsRequireReplace(context.Background(), filters, state, plan); !requiresReplace {
+			t.Fatal("expected a swap across DataSources to require replace")
+		}
+	}
+
+	for filterId, count := range described {
+		if count != 1 {
+			t.Errorf("expected Filter %s to be described once, got %d", filterId, count)
+		}
+	}
+}
+
+// testModifyPlan runs the ModifyPlan of r for an update from current to plan.
+func testModifyPlan(t *testing.T, r fwresource.ResourceWithModifyPlan, current any, plan any) fwresource.ModifyPlanResponse {
+	ctx := context.Background()
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	schemaType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaType, nil)}
+	planned := tfsdk.Plan{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaType, nil)}
+
+	var diags diag.Diagnostics
+	diags.Append(state.Set(ctx, current)...)
+	diags.Append(planned.Set(ctx, plan)...)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics building the plan: %v", diags)
+	}
+
+	resp := fwresource.ModifyPlanResponse{Plan: planned}
+	r.ModifyPlan(ctx, fwresource.ModifyPlanRequest{State: state, Plan: planned}, &resp)
+	return resp
+}
+
+// hasDiagnostic reports whether diags holds a diagnostic with the given severity and summary.
+func hasDiagnostic(diags diag.Diagnostics, severity diag.Severity, summary string) bool {
+	for _, d := range diags {
+		if d.Severity() == severity && d.Summary() == summary {
+			return true
+		}
+	}
+	return false
+}
+
+// testDataDestinationModel returns the state of a READY DataDestination using the given Filters.
+func testDataDestinationModel(filterIds ...string) dataDestinationResourceModel {
+	elements := make([]attr.Value, 0, len(filterIds))
+	for _, filterId := range filterIds {
+		elements = append(elements, types.StringValue(filterId))
+	}
+
+	return dataDestinationResourceModel{
+		FilterIds:           types.SetValueMust(types.StringType, elements),
+		Description:         types.StringValue("destination"),
+		DestinationEndpoint: types.StringValue("https://example.com/data"),
+		Username:            types.StringValue("username"),
+		Password:            types.StringValue("password"),
+		PreventReplay:       types.BoolValue(false),
+		DeletionProtection:  types.BoolValue(false),
+		OnCreateConflict:    types.StringValue("error"),
+		State:               types.StringValue("READY"),
+		ResourceId:          types.StringValue("destination-1"),
+	}
+}
+
+func TestDataDestinationModifyPlanUnknownFilterIds(t *testing.T) {
+	server, _ := testFilterServer(t, map[string]string{"filter-a1": "source-a"})
+
+	tests := []struct {
+		name      string
+		filterIds types.Set
+	}{
+		{"unknown filter_ids", types.SetUnknown(types.StringType)},
+		{"replaced Filter", types.SetValueMust(types.StringType, []attr.Value{types.StringUnknown()})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// prevent_replay only blocks replacements, so it must not block the in place update.
+			current := testDataDestinationModel("filter-a1")
+			current.PreventReplay = types.BoolValue(true)
+			plan := current
+			plan.FilterIds = test.filterIds
+			plan.State = types.StringUnknown()
+
+			r := &DataDestinationResource{client: testAmbarClient(server)}
+			resp := testModifyPlan(t, r, current, plan)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+			}
+			if !hasDiagnostic(resp.Diagnostics, diag.SeverityWarning, "DataDestination Filters not known until apply") {
+				t.Errorf("expected the unknown Filters warning, got: %v", resp.Diagnostics)
+			}
+			if len(resp.RequiresReplace) != 0 {
+				t.Errorf("expected an in place update, got replacement by: %v", resp.RequiresReplace)
+			}
+		})
+	}
+}
+
+func TestDataDestinationModifyPlanDescribesFiltersOnce(t *testing.T) {
+	server, described := testFilterServer(t, map[string]string{
+		"filter-a1": "source-a",
+		"filter-b1": "source-b",
+	})
+
+	current := testDataDestinationModel("filter-a1")
+	plan := testDataDestinationModel("filter-b1")
+	plan.State = types.StringUnknown()
+
+	r := &DataDestinationResource{client: testAmbarClient(server)}
+	resp := testModifyPlan(t, r, current, plan)
+
+	if !hasDiagnostic(resp.Diagnostics, diag.SeverityWarning, "DataDestination replacement will replay records") {
+		t.Errorf("expected the replay warning, got: %v", resp.Diagnostics)
+	}
+	if !resp.RequiresReplace.Contains(path.Root("filter_ids")) {
+		t.Errorf("expected filter_ids to require replace, got: %v", resp.RequiresReplace)
+	}
+	for _, filterId := range []string{"filter-a1", "filter-b1"} {
+		if described[filterId] != 1 {
+			t.Errorf("expected Filter %s to be described once, got %d", filterId, described[filterId])
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
@@ -17,6 +18,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +28,7 @@ import (
 var _ resource.Resource = &dataSourceResource{}
 var _ resource.ResourceWithImportState = &dataSourceResource{}
 var _ resource.ResourceWithConfigure = &dataSourceResource{}
+var _ resource.ResourceWithModifyPlan = &dataSourceResource{}
 
 func NewDataSourceResource() resource.Resource {
 	return &dataSourceResource{}
@@ -33,16 +36,22 @@ func NewDataSourceResource() resource.Resource {
 
 // dataSourceResource defines the resource implementation.
 type dataSourceResource struct {
-	client *Ambar.APIClient
+	client             *Ambar.APIClient
+	ownershipTag       string
+	readOnly           bool
+	defaultDescription descriptionDecoration
 }
 
 // dataSourceResourceModel describes the resource data model.
 type dataSourceResourceModel struct {
-	DataSourceType   types.String `tfsdk:"data_source_type"`
-	Description      types.String `tfsdk:"description"`
-	DataSourceConfig types.Map    `tfsdk:"data_source_config"`
-	State            types.String `tfsdk:"state"`
-	ResourceId       types.String `tfsdk:"resource_id"`
+	DataSourceType     types.String `tfsdk:"data_source_type"`
+	Description        types.String `tfsdk:"description"`
+	DataSourceConfig   types.Map    `tfsdk:"data_source_config"`
+	PreventReplay      types.Bool   `tfsdk:"prevent_replay"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	OnCreateConflict   types.String `tfsdk:"on_create_conflict"`
+	State              types.String `tfsdk:"state"`
+	ResourceId         types.String `tfsdk:"resource_id"`
 }
 
 func (r *dataSourceResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -80,48 +89,28 @@ func (r *dataSourceResource) Schema(ctx context.Context, req resource.SchemaRequ
 				PlanModifiers: []planmodifier.Map{
 					mapplanmodifier.RequiresReplaceIf(
 						func(ctx context.Context, req planmodifier.MapRequest, resp *mapplanmodifier.RequiresReplaceIfFuncResponse) {
-							var current dataSourceResourceModel
-							// Read Terraform prior state data into the model
-							resp.Diagnostics.Append(req.State.Get(ctx, &current)...)
-
-							if resp.Diagnostics.HasError() {
-								return
-							}
-
-							var plan dataSourceResourceModel
-							// Read Terraform plan data into the model
-							resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
-
-							if resp.Diagnostics.HasError() {
-								return
-							}
-
-							// Loop through all the values and compare. If anything other than username / password has changed we will need to
-							// flag to replace the resource.
-							for key, value := range plan.DataSourceConfig.Elements() {
-								switch key {
-								case
-									"username",
-									"password",
-									"hostname",
-									"hostPort",
-									"tlsTerminationOverrideHost":
-									tflog.Info(ctx, "Detected change in config which does not require replace")
-									continue
-								default:
-									if value.String() != current.DataSourceConfig.Elements()[key].String() {
-										tflog.Info(ctx, "Detected change in config which requires replace")
-										resp.RequiresReplace = true
-										return
-									}
-								}
-							}
+							resp.RequiresReplace = doesDataSourceConfigRequireReplace(ctx, req.StateValue, req.PlanValue)
 						},
 						"Only some config value updates are supported. For a list of supported values, see Ambar docs.",
 						"Only some config value updates are supported. For a list of supported values, see Ambar docs.",
 					),
 				},
 			},
+			"prevent_replay": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, plans which would replace this DataSource fail instead. Replacing a DataSource replaces the Filters and DataDestinations using it, which then replay every record from the beginning. Defaults to `false`.",
+				Description:         "When true, plans which would replace this DataSource fail instead. Replacing a DataSource replaces the Filters and DataDestinations using it, which then replay every record from the beginning. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"deletion_protection": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, plans which would destroy or replace this DataSource fail instead. Set to `false` and apply before destroying the DataSource. Defaults to `false`.",
+				Description:         "When true, plans which would destroy or replace this DataSource fail instead. Set to false and apply before destroying the DataSource. Defaults to false.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"on_create_conflict": onCreateConflictAttribute("DataSource"),
 			"state": schema.StringAttribute{
 				MarkdownDescription: "The current state of the Ambar resource.",
 				Description:         "The current state of the Ambar resource.",
@@ -144,6 +133,58 @@ func (r *dataSourceResource) Schema(ctx context.Context, req resource.SchemaRequ
 	}
 }
 
+// dataSourceMatches reports whether an existing DataSource has the type, description and config being created.
+// Credentials are not returned by Ambar, so they are not compared.
+func dataSourceMatches(createDataSource Ambar.CreateDataSourceRequest, describeResourceResponse *Ambar.DataSource) bool {
+	if describeResourceResponse.DataSourceType != createDataSource.DataSourceType ||
+		stringOrEmpty(describeResourceResponse.Description) != stringOrEmpty(createDataSource.Description) {
+		return false
+	}
+
+	for key, value := range createDataSource.DataSourceConfig {
+		if key == "username" || key == "password" {
+			continue
+		}
+
+		described, ok := describeResourceResponse.DataSourceConfig[key]
+		if !ok || fmt.Sprint(described) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// doesDataSourceConfigRequireReplace loops through all the config values and compares them. If anything other than
+// the connection details and credentials has changed we will need to flag to replace the resource. A config which is
+// not known until apply can't be checked, so it is replaced as well.
+func doesDataSourceConfigRequireReplace(ctx context.Context, current types.Map, plan types.Map) bool {
+	if plan.IsUnknown() {
+		tflog.Info(ctx, "Detected config which is not known yet, which requires replace")
+		return true
+	}
+
+	for key, value := range plan.Elements() {
+		switch key {
+		case
+			"username",
+			"password",
+			"hostname",
+			"hostPort",
+			"tlsTerminationOverrideHost":
+			tflog.Info(ctx, "Detected change in config which does not require replace")
+			continue
+		default:
+			if value.String() != current.Elements()[key].String() {
+				tflog.Info(ctx, "Detected change in config which requires replace")
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func doesStateRequireReplace(ctx context.Context, request planmodifier.StringRequest, response *stringplanmodifier.RequiresReplaceIfFuncResponse) {
 	var data dataSourceResourceModel
 
@@ -152,33 +193,158 @@ func doesStateRequireReplace(ctx context.Context, request planmodifier.StringReq
 
 	tflog.Debug(ctx, "Got value for state: "+data.State.String())
 
-	if data.State.String() == "FAILED" {
+	if dataSourceStateRequiresReplace(data.State) {
 		response.RequiresReplace = true
 		return
 	}
 }
 
+// dataSourceStateRequiresReplace reports whether the DataSource is in a state which is not valid for further use.
+func dataSourceStateRequiresReplace(state types.String) bool {
+	return state.ValueString() == "FAILED"
+}
+
 func (r *dataSourceResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*Ambar.APIClient)
+	providerData, ok := req.ProviderData.(*ambarProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *Ambar.APIClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ambarProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = providerData.Client
+	r.ownershipTag = providerData.OwnershipTag
+	r.readOnly = providerData.ReadOnly
+	r.defaultDescription = providerData.DefaultDescription
+}
+
+func (r *dataSourceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing can be replaced or destroyed when creating the DataSource.
+	if req.State.Raw.IsNull() || req.Plan.Raw.Equal(req.State.Raw) {
+		return
+	}
+
+	var current dataSourceResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &current)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if req.Plan.Raw.IsNull() {
+		checkResourceGuards(&resp.Diagnostics, "DataSource", current.ResourceId.ValueString(), nil, current.PreventReplay.ValueBool(), current.DeletionProtection.ValueBool())
+		return
+	}
+
+	var plan dataSourceResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	replacedBy := dataSourceReplacedBy(ctx, current, plan)
+	if len(replacedBy) == 0 {
+		return
+	}
+
+	// Guards enabled in either the state or the plan apply, so turning one off has to be applied on its own first.
+	if !checkResourceGuards(&resp.Diagnostics, "DataSource", current.ResourceId.ValueString(), replacedBy,
+		current.PreventReplay.ValueBool() || plan.PreventReplay.ValueBool(),
+		current.DeletionProtection.ValueBool() || plan.DeletionProtection.ValueBool()) {
+		return
+	}
+
+	affected := "any DataDestinations using its Filters"
+	if destinationIds := r.describeDataSourceDestinations(ctx, current.ResourceId.ValueString()); len(destinationIds) > 0 {
+		affected = "the DataDestinations " + strings.Join(destinationIds, ", ")
+	}
+
+	resp.Diagnostics.AddWarning(
+		"DataSource replacement will replay records",
+		fmt.Sprintf("This change replaces the DataSource %s. Filters referencing it must be replaced as well, which replaces "+
+			"%s. Those DataDestinations lose their delivery position, and every record from the new DataSource will be "+
+			"delivered to them from the beginning. Make sure their endpoints can handle the replay before applying.",
+			current.ResourceId.ValueString(), affected),
+	)
+}
+
+// dataSourceReplacedBy returns the attributes whose planned changes require the DataSource to be replaced, matching
+// the RequiresReplace plan modifiers of the schema.
+func dataSourceReplacedBy(ctx context.Context, current dataSourceResourceModel, plan dataSourceResourceModel) []string {
+	var replacedBy []string
+
+	if !plan.DataSourceType.Equal(current.DataSourceType) {
+		replacedBy = append(replacedBy, "data_source_type")
+	}
+
+	if !plan.Description.Equal(current.Description) {
+		replacedBy = append(replacedBy, "description")
+	}
+
+	if !plan.DataSourceConfig.Equal(current.DataSourceConfig) && doesDataSourceConfigRequireReplace(ctx, current.DataSourceConfig, plan.DataSourceConfig) {
+		replacedBy = append(replacedBy, "data_source_config")
+	}
+
+	if !plan.State.Equal(current.State) && dataSourceStateRequiresReplace(current.State) {
+		replacedBy = append(replacedBy, "state")
+	}
+
+	return replacedBy
+}
+
+// describeDataSourceDestinations returns the ids of the DataDestinations receiving records from a DataSource through
+// any of its Filters. Errors are only logged, as the resulting warning still applies without the list.
+func (r *dataSourceResource) describeDataSourceDestinations(ctx context.Context, resourceId string) []string {
+	if r.client == nil {
+		return nil
+	}
+
+	var describeDataSource Ambar.DescribeResourceRequest
+	describeDataSource.ResourceId = resourceId
+
+	dataSource, _, err := r.client.AmbarAPI.DescribeDataSource(ctx).DescribeResourceRequest(describeDataSource).Execute()
+	if err != nil {
+		tflog.Debug(ctx, "Unable to describe DataSource "+resourceId+" to find its DataDestinations: "+err.Error())
+		return nil
+	}
+
+	var destinationIds []string
+	for _, filterId := range dataSource.FilterIds {
+		var describeFilter Ambar.DescribeResourceRequest
+		describeFilter.ResourceId = filterId
+
+		filter, _, err := r.client.AmbarAPI.DescribeFilter(ctx).DescribeResourceRequest(describeFilter).Execute()
+		if err != nil {
+			tflog.Debug(ctx, "Unable to describe Filter "+filterId+" to find its DataDestinations: "+err.Error())
+			continue
+		}
+
+		for _, destinationId := range filter.DataDestinationsUsingFilter {
+			if !slices.Contains(destinationIds, destinationId) {
+				destinationIds = append(destinationIds, destinationId)
+			}
+		}
+	}
+	slices.Sort(destinationIds)
+
+	return destinationIds
 }
 
 func (r *dataSourceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "create", "DataSource") {
+		return
+	}
+
 	// Retrieve values from plan
 	var plan dataSourceResourceModel
 	diags := req.Plan.Get(ctx, &plan)
@@ -190,7 +356,8 @@ func (r *dataSourceResource) Create(ctx context.Context, req resource.CreateRequ
 	// Generate API request body from plan
 	var createDataSource Ambar.CreateDataSourceRequest
 	createDataSource.DataSourceType = plan.DataSourceType.ValueString()
-	createDataSource.Description = plan.Description.ValueStringPointer()
+	decoration := r.defaultDescription.resolve("ambar_data_source")
+	createDataSource.Description = withOwnershipTag(decoration.apply(plan.Description.ValueStringPointer()), r.ownershipTag)
 
 	// Handle dynamic DataSource resource configuration map
 	createDataSource.DataSourceConfig = make(map[string]string)
@@ -199,32 +366,64 @@ func (r *dataSourceResource) Create(ctx context.Context, req resource.CreateRequ
 		createDataSource.DataSourceConfig[key] = strings.Trim(value.String(), "\"")
 	}
 
-	// Create the API call and execute it
-	createResourceResponse, httpResponse, err := r.client.AmbarAPI.CreateDataSource(ctx).CreateDataSourceRequest(createDataSource).Execute()
-	if err != nil || createResourceResponse == nil || httpResponse == nil {
-		tflog.Debug(ctx, "StatusCode: "+httpResponse.Status)
-		httpBody, _ := io.ReadAll(httpResponse.Body)
-		errString := string(httpBody)
-		tflog.Debug(ctx, errString)
-		resp.Diagnostics.AddError(
-			"Error creating DataSource",
-			"Could not create DataSource: "+AmbarApiErrorToTerraformErrorString(errString),
-		)
+	// Look for a DataSource left behind by an earlier attempt before creating a new one, remembering the state of each
+	// candidate so an adopted DataSource can be saved to state straight away
+	candidateStates := map[string]string{}
+	adoptedId := findCreateConflict(ctx, r.client, &resp.Diagnostics, plan.OnCreateConflict, "DataSource", createDataSource.Description, func(resourceId string) (bool, error) {
+		var describeDataSource Ambar.DescribeResourceRequest
+		describeDataSource.ResourceId = resourceId
+
+		describeResourceResponse, _, err := r.client.AmbarAPI.DescribeDataSource(ctx).DescribeResourceRequest(describeDataSource).Execute()
+		if err != nil {
+			return false, err
+		}
+		candidateStates[resourceId] = describeResourceResponse.State
+		return dataSourceMatches(createDataSource, describeResourceResponse), nil
+	})
+
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Map response body to schema and populate Computed attribute values
-	plan.ResourceId = types.StringValue(createResourceResponse.ResourceId)
-	plan.State = types.StringValue(createResourceResponse.State)
+	if adoptedId != "" {
+		plan.ResourceId = types.StringValue(adoptedId)
+		plan.State = types.StringValue(candidateStates[adoptedId])
+
+		// Save the adopted DataSource to state, so it is not adopted again if waiting for it fails
+		diags = resp.State.Set(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+	} else {
+		// Create the API call and execute it
+		createResourceResponse, httpResponse, err := r.client.AmbarAPI.CreateDataSource(ctx).CreateDataSourceRequest(createDataSource).Execute()
+		if err != nil || createResourceResponse == nil || httpResponse == nil {
+			tflog.Debug(ctx, "StatusCode: "+httpResponse.Status)
+			httpBody, _ := io.ReadAll(httpResponse.Body)
+			errString := string(httpBody)
+			tflog.Debug(ctx, errString)
+			resp.Diagnostics.AddError(
+				"Error creating DataSource",
+				"Could not create DataSource: "+AmbarApiErrorToTerraformErrorString(errString),
+			)
+			return
+		}
 
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, &plan)
-	resp.Diagnostics.Append(diags...)
+		// Map response body to schema and populate Computed attribute values
+		plan.ResourceId = types.StringValue(createResourceResponse.ResourceId)
+		plan.State = types.StringValue(createResourceResponse.State)
+
+		// Set state to fully populated data
+		diags = resp.State.Set(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	// Remember the decoration used, so Read can remove it even if the provider configuration changes
+	resp.Diagnostics.Append(decoration.save(ctx, resp.Private)...)
 
 	var describeDataSource Ambar.DescribeResourceRequest
-	describeDataSource.ResourceId = createResourceResponse.ResourceId
+	describeDataSource.ResourceId = plan.ResourceId.ValueString()
 
 	var describeResourceResponse *Ambar.DataSource
+	var err error
 
 	for {
 		time.Sleep(10 * time.Second)
@@ -232,6 +431,10 @@ func (r *dataSourceResource) Create(ctx context.Context, req resource.CreateRequ
 		describeResourceResponse, _, err = r.client.AmbarAPI.DescribeDataSource(ctx).DescribeResourceRequest(describeDataSource).Execute()
 		if err != nil {
 			tflog.Error(ctx, "Got error!"+err.Error())
+			resp.Diagnostics.AddError(
+				"Error waiting for DataSource",
+				"Could not describe DataSource "+describeDataSource.ResourceId+" while waiting for it to become READY: "+err.Error(),
+			)
 			return
 		}
 
@@ -303,15 +506,16 @@ func (r *dataSourceResource) Read(ctx context.Context, req resource.ReadRequest,
 	// not return some sensitive data like credential information
 	data.State = types.StringValue(describeResourceResponse.State)
 	data.DataSourceType = types.StringValue(describeResourceResponse.DataSourceType)
-	data.Description = types.StringPointerValue(describeResourceResponse.Description)
+	decoration, diags := loadDescriptionDecoration(ctx, req.Private, r.defaultDescription.resolve("ambar_data_source"))
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(decoration.save(ctx, resp.Private)...)
+	data.Description = types.StringPointerValue(decoration.strip(withoutOwnershipTag(describeResourceResponse.Description)))
 
 	// Describe calls will not return sensitive credentials. So we will need to carry the local value forward to prevent
 	// always doing a replacement on each apply.
 	username := data.DataSourceConfig.Elements()["username"].String()
 	password := data.DataSourceConfig.Elements()["password"].String()
 
-	tflog.Info(ctx, "Got value for username from state: "+username)
-
 	// Add back credentials to prevent recreation issues.
 	describeResourceResponse.DataSourceConfig["username"] = strings.Trim(username, "\"")
 	describeResourceResponse.DataSourceConfig["password"] = strings.Trim(password, "\"")
@@ -319,11 +523,26 @@ func (r *dataSourceResource) Read(ctx context.Context, req resource.ReadRequest,
 	// remap the config from the describe call. This will be missing credentials
 	data.DataSourceConfig, _ = types.MapValueFrom(ctx, types.StringType, describeResourceResponse.DataSourceConfig)
 
+	// The guards only exist in Terraform, so imported or older state will not have them set yet.
+	if data.PreventReplay.IsNull() {
+		data.PreventReplay = types.BoolValue(false)
+	}
+	if data.DeletionProtection.IsNull() {
+		data.DeletionProtection = types.BoolValue(false)
+	}
+	if data.OnCreateConflict.IsNull() {
+		data.OnCreateConflict = types.StringValue(onCreateConflictCreate)
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *dataSourceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "update", "DataSource") {
+		return
+	}
+
 	// Ambar does not support resource updates on DataSources for now.
 	var plan dataSourceResourceModel
 	var current dataSourceResourceModel
@@ -336,6 +555,13 @@ func (r *dataSourceResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
+	// Changes to prevent_replay or deletion_protection alone only need to be saved to state.
+	if plan.DataSourceConfig.Equal(current.DataSourceConfig) {
+		plan.State = current.State
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		return
+	}
+
 	// We need to validate we can perform the change in a single operation, so only credentials should be changed, or only
 	// non-credential attributes should be changed
 	var credentialsUpdated = plan.DataSourceConfig.Elements()["username"].String() != current.DataSourceConfig.Elements()["username"].String() ||
@@ -403,6 +629,10 @@ func (r *dataSourceResource) Update(ctx context.Context, req resource.UpdateRequ
 }
 
 func (r *dataSourceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "delete", "DataSource") {
+		return
+	}
+
 	var data dataSourceResourceModel
 
 	// Read Terraform prior state data into the model
@@ -1,8 +1,14 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"context"
+	"slices"
 	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 const (
@@ -41,3 +47,85 @@ func TestAccAmbarDataSourceResource(t *testing.T) {
 		},
 	})
 }
+
+func TestDataSourceReplacedBy(t *testing.T) {
+	config := func(tableName string) types.Map {
+		return types.MapValueMust(types.StringType, map[string]attr.Value{
+			"hostname":  types.StringValue("hostname"),
+			"tableName": types.StringValue(tableName),
+		})
+	}
+
+	current := dataSourceResourceModel{
+		DataSourceType:   types.StringValue("postgres"),
+		Description:      types.StringValue("source"),
+		DataSourceConfig: config("events"),
+		State:            types.StringValue("READY"),
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(current *dataSourceResourceModel, plan *dataSourceResourceModel)
+		expected []string
+	}{
+		{"no change", func(current *dataSourceResourceModel, plan *dataSourceResourceModel) {}, nil},
+		{"description", func(current *dataSourceResourceModel, plan *dataSourceResourceModel) {
+			plan.Description = types.StringValue("renamed")
+		}, []string{"description"}},
+		{"data_source_config", func(current *dataSourceResourceModel, plan *dataSourceResourceModel) {
+			plan.DataSourceConfig = config("other_events")
+		}, []string{"data_source_config"}},
+		{"unknown data_source_config", func(current *dataSourceResourceModel, plan *dataSourceResourceModel) {
+			plan.DataSourceConfig = types.MapUnknown(types.StringType)
+		}, []string{"data_source_config"}},
+		{"updatable data_source_config", func(current *dataSourceResourceModel, plan *dataSourceResourceModel) {
+			plan.DataSourceConfig = types.MapValueMust(types.StringType, map[string]attr.Value{
+				"hostname":  types.StringValue("other-hostname"),
+				"tableName": types.StringValue("events"),
+			})
+		}, nil},
+		{"FAILED state", func(current *dataSourceResourceModel, plan *dataSourceResourceModel) {
+			current.State = types.StringValue("FAILED")
+			plan.State = types.StringUnknown()
+		}, []string{"state"}},
+		{"READY state", func(current *dataSourceResourceModel, plan *dataSourceResourceModel) {
+			plan.State = types.StringUnknown()
+		}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			current, plan := current, current
+			test.modify(&current, &plan)
+
+			if replacedBy := dataSourceReplacedBy(context.Background(), current, plan); !slices.Equal(replacedBy, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, replacedBy)
+			}
+		})
+	}
+}
+
+func TestDataSourceModifyPlanUnknownConfig(t *testing.T) {
+	current := dataSourceResourceModel{
+		DataSourceType:     types.StringValue("postgres"),
+		Description:        types.StringValue("source"),
+		DataSourceConfig:   types.MapValueMust(types.StringType, map[string]attr.Value{"tableName": types.StringValue("events")}),
+		PreventReplay:      types.BoolValue(false),
+		DeletionProtection: types.BoolValue(false),
+		OnCreateConflict:   types.StringValue("error"),
+		State:              types.StringValue("READY"),
+		ResourceId:         types.StringValue("source-1"),
+	}
+	plan := current
+	plan.DataSourceConfig = types.MapUnknown(types.StringType)
+	plan.State = types.StringUnknown()
+
+	resp := testModifyPlan(t, &dataSourceResource{}, current, plan)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if !hasDiagnostic(resp.Diagnostics, diag.SeverityWarning, "DataSource replacement will replay records") {
+		t.Errorf("expected the replay warning, got: %v", resp.Diagnostics)
+	}
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// The provider default_description block decorates the description of every DataSource, Filter and DataDestination
+// with a prefix and suffix, such as the environment name. Descriptions can not be updated in place, so the decoration
+// written when a resource is created is kept in its private state and removed again on Read. Changing the decoration
+// later only affects resources created afterwards, rather than planning to replace every existing resource.
+
+// descriptionDecorationKey is the private state key holding the decoration applied to a resource's description.
+const descriptionDecorationKey = "description_decoration"
+
+// descriptionDecoration is the prefix and suffix added to a description.
+type descriptionDecoration struct {
+	Prefix string `json:"prefix"`
+	Suffix string `json:"suffix"`
+}
+
+// privateState is implemented by the private state of resource requests and responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// resolve replaces the template variables of the configured prefix and suffix for a resource type.
+func (d descriptionDecoration) resolve(resourceType string) descriptionDecoration {
+	replacer := strings.NewReplacer(
+		"{workspace}", terraformWorkspace(),
+		"{resource_type}", resourceType,
+	)
+
+	return descriptionDecoration{
+		Prefix: replacer.Replace(d.Prefix),
+		Suffix: replacer.Replace(d.Suffix),
+	}
+}
+
+// apply decorates a description being written to Ambar.
+func (d descriptionDecoration) apply(description *string) *string {
+	if d.Prefix == "" && d.Suffix == "" {
+		return description
+	}
+
+	decorated := d.Prefix + stringOrEmpty(description) + d.Suffix
+	return &decorated
+}
+
+// strip removes the decoration from a description read from Ambar. Descriptions without the decoration, such as those
+// changed outside of Terraform, are returned unchanged so the change shows up in the plan.
+func (d descriptionDecoration) strip(description *string) *string {
+	if description == nil || (d.Prefix == "" && d.Suffix == "") {
+		return description
+	}
+
+	if len(*description) < len(d.Prefix)+len(d.Suffix) || !strings.HasPrefix(*description, d.Prefix) || !strings.HasSuffix(*description, d.Suffix) {
+		return description
+	}
+
+	// A description made up of only the decoration was written for a resource without a description.
+	stripped := (*description)[len(d.Prefix) : len(*description)-len(d.Suffix)]
+	if stripped == "" {
+		return nil
+	}
+	return &stripped
+}
+
+// save records the decoration applied to a resource in its private state.
+func (d descriptionDecoration) save(ctx context.Context, private privateState) diag.Diagnostics {
+	value, err := json.Marshal(d)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Unable to save description decoration", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, descriptionDecorationKey, value)
+}
+
+// loadDescriptionDecoration returns the decoration recorded in the private state of a resource, or fallback for
+// resources without one, such as those which were just imported.
+func loadDescriptionDecoration(ctx context.Context, private privateState, fallback descriptionDecoration) (descriptionDecoration, diag.Diagnostics) {
+	value, diags := private.GetKey(ctx, descriptionDecorationKey)
+	if diags.HasError() || len(value) == 0 {
+		return fallback, diags
+	}
+
+	var decoration descriptionDecoration
+	if err := json.Unmarshal(value, &decoration); err != nil {
+		return fallback, diags
+	}
+	return decoration, diags
+}
+
+// terraformWorkspace returns the name of the selected Terraform workspace. Terraform does not pass the workspace to
+// providers, so this follows the same lookup as Terraform itself: the TF_WORKSPACE environment variable, then the
+// environment file in the data directory of the working directory Terraform runs the provider from.
+func terraformWorkspace() string {
+	if workspace := os.Getenv("TF_WORKSPACE"); workspace != "" {
+		return workspace
+	}
+
+	dataDir := os.Getenv("TF_DATA_DIR")
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+
+	if contents, err := os.ReadFile(filepath.Join(dataDir, "environment")); err == nil {
+		if workspace := strings.TrimSpace(string(contents)); workspace != "" {
+			return workspace
+		}
+	}
+
+	return "default"
+}
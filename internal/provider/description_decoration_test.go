@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestDescriptionDecoration(t *testing.T) {
+	description := "My DataSource"
+	decoration := descriptionDecoration{Prefix: "[prod] ", Suffix: " (managed)"}
+
+	tests := []struct {
+		name        string
+		description *string
+		decorated   string
+	}{
+		{"description", &description, "[prod] My DataSource (managed)"},
+		{"no description", nil, "[prod]  (managed)"},
+	}
+
+	for _, test := range tests {
+		decorated := decoration.apply(test.description)
+		if decorated == nil || *decorated != test.decorated {
+			t.Errorf("%s: apply returned %v, expected %q", test.name, decorated, test.decorated)
+			continue
+		}
+
+		stripped := decoration.strip(decorated)
+		if (stripped == nil) != (test.description == nil) || (stripped != nil && *stripped != *test.description) {
+			t.Errorf("%s: strip(%q) returned %v, expected %v", test.name, *decorated, stripped, test.description)
+		}
+	}
+
+	// Descriptions changed outside of Terraform are left alone, so the change shows up in the plan
+	changed := "Changed in the Ambar console"
+	if stripped := decoration.strip(&changed); stripped != &changed {
+		t.Errorf("strip expected a description without the decoration to be unchanged")
+	}
+
+	if undecorated := (descriptionDecoration{}).apply(&description); undecorated != &description {
+		t.Errorf("apply expected the description to be unchanged without a decoration")
+	}
+}
+
+func TestDescriptionDecorationResolve(t *testing.T) {
+	t.Setenv("TF_WORKSPACE", "staging")
+
+	decoration := descriptionDecoration{Prefix: "{workspace}/", Suffix: " ({resource_type})"}.resolve("ambar_filter")
+	if decoration.Prefix != "staging/" || decoration.Suffix != " (ambar_filter)" {
+		t.Errorf("resolve returned %+v", decoration)
+	}
+}
+
+func TestTerraformWorkspace(t *testing.T) {
+	t.Setenv("TF_WORKSPACE", "")
+	t.Setenv("TF_DATA_DIR", t.TempDir())
+
+	if workspace := terraformWorkspace(); workspace != "default" {
+		t.Errorf("terraformWorkspace returned %q, expected the default workspace", workspace)
+	}
+}
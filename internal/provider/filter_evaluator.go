@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file evaluates parsed filter statements against sample records, so filters can be tested during plan rather
+// than by watching what gets delivered to a DataDestination. Record values are provided as strings, the same way
+// they are configured in Terraform, and are converted when compared with a number or boolean, mirroring how Ambar
+// compares column values with filter literals:
+//
+//   - lookup of a column missing from the record returns null.
+//   - == and != against a number or boolean literal compare the converted record value, a record value which cannot
+//     be converted is never equal.
+//   - <, <=, > and >= compare numerically when both sides are numbers, and lexicographically when both are strings.
+//     Any comparison involving null, or values which cannot be converted, is false.
+//   - String functions return false, or null for substring, when given a null value.
+
+// filterRecord is a sample record, keyed by column name.
+type filterRecord map[string]string
+
+// evaluateFilter reports whether the record matches the filter.
+func evaluateFilter(expr filterExpr, record filterRecord) (bool, error) {
+	value, err := evaluateFilterExpr(expr, record)
+	if err != nil {
+		return false, err
+	}
+	return filterTruthy(expr, value)
+}
+
+func evaluateFilterExpr(expr filterExpr, record filterRecord) (any, error) {
+	switch e := expr.(type) {
+	case *filterLiteral:
+		return e.Value, nil
+
+	case *filterUnary:
+		operand, err := evaluateFilter(e.Operand, record)
+		if err != nil {
+			return nil, err
+		}
+		return !operand, nil
+
+	case *filterBinary:
+		switch e.Op {
+		case "&&", "||":
+			left, err := evaluateFilter(e.Left, record)
+			if err != nil {
+				return nil, err
+			}
+			if (e.Op == "&&" && !left) || (e.Op == "||" && left) {
+				return left, nil
+			}
+			return evaluateFilter(e.Right, record)
+		}
+
+		left, err := evaluateFilterExpr(e.Left, record)
+		if err != nil {
+			return nil, err
+		}
+		right, err := evaluateFilterExpr(e.Right, record)
+		if err != nil {
+			return nil, err
+		}
+		return compareFilterValues(e.Op, left, right), nil
+
+	case *filterCall:
+		if field, ok := filterLookupField(e); ok {
+			if value, ok := record[field]; ok {
+				return value, nil
+			}
+			return nil, nil
+		}
+
+		args := make([]any, 0, len(e.Args))
+		for _, arg := range e.Args {
+			value, err := evaluateFilterExpr(arg, record)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, value)
+		}
+		return callFilterFunction(e, args)
+	}
+
+	return nil, &filterSyntaxError{Pos: expr.Position(), Message: "unsupported expression"}
+}
+
+// filterTruthy converts the result of an expression used as a condition into a bool. Record values used directly as
+// conditions must hold a boolean.
+func filterTruthy(expr filterExpr, value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	case nil:
+		return false, nil
+	}
+	return false, &filterSyntaxError{Pos: expr.Position(), Message: fmt.Sprintf("%s is %s, expected a boolean", renderFilter(expr), formatFilterValue(value))}
+}
+
+func compareFilterValues(op string, left, right any) bool {
+	// Convert record values to the type of the literal they are compared with.
+	left, right = coerceFilterValue(left, right), coerceFilterValue(right, left)
+
+	switch op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	}
+
+	var order int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case l < r:
+			order = -1
+		case l > r:
+			order = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false
+		}
+		order = strings.Compare(l, r)
+	default:
+		return false
+	}
+
+	switch op {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
+
+// coerceFilterValue converts a string value to the type of other when other is a number or boolean. Strings which
+// cannot be converted are returned unchanged, so they compare as unequal.
+func coerceFilterValue(value, other any) any {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	switch other.(type) {
+	case float64:
+		if number, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			return number
+		}
+	case bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func callFilterFunction(call *filterCall, args []any) (any, error) {
+	// Functions missing from filterFunctions pass the parser with any arguments, so the name is checked before any
+	// argument is used.
+	switch call.Name {
+	case "substring":
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, nil
+		}
+		start, startOk := coerceFilterValue(args[1], 0.0).(float64)
+		end, endOk := coerceFilterValue(args[2], 0.0).(float64)
+		if !startOk || !endOk {
+			return nil, &filterSyntaxError{Pos: call.Pos, Message: "substring expects numeric start and end positions"}
+		}
+		runes := []rune(s)
+		from, to := max(0, min(int(start), len(runes))), max(0, min(int(end), len(runes)))
+		if from > to {
+			return "", nil
+		}
+		return string(runes[from:to]), nil
+	case "string_contains", "string_starts_with", "string_ends_with":
+	default:
+		return nil, &filterSyntaxError{Pos: call.Pos, Message: fmt.Sprintf("unknown function %q", call.Name)}
+	}
+
+	s, sOk := args[0].(string)
+	search, searchOk := args[1].(string)
+	if !sOk || !searchOk {
+		return false, nil
+	}
+
+	switch call.Name {
+	case "string_contains":
+		return strings.Contains(s, search), nil
+	case "string_starts_with":
+		return strings.HasPrefix(s, search), nil
+	default:
+		return strings.HasSuffix(s, search), nil
+	}
+}
+
+// explainFilter describes how each predicate of the filter evaluated against the record, one line per predicate, to
+// help track down why a record did or did not match. Negations are explained after the predicates they negate, so
+// the line showing the inverted result is not missing.
+func explainFilter(expr filterExpr, record filterRecord) []string {
+	switch e := expr.(type) {
+	case *filterUnary:
+		return append(explainFilter(e.Operand, record), explainPredicate(expr, record))
+	case *filterBinary:
+		if e.Op == "&&" || e.Op == "||" {
+			return append(explainFilter(e.Left, record), explainFilter(e.Right, record)...)
+		}
+	case *filterLiteral:
+		return nil
+	}
+
+	return []string{explainPredicate(expr, record)}
+}
+
+// explainPredicate describes how a single predicate evaluated against the record, along with the fields it looks up.
+func explainPredicate(expr filterExpr, record filterRecord) string {
+	line := renderFilter(expr)
+	if result, err := evaluateFilter(expr, record); err != nil {
+		line += " => error: " + filterErrorMessage(err)
+	} else {
+		line += " => " + strconv.FormatBool(result)
+	}
+
+	var values []string
+	for _, lookup := range filterLookups(expr) {
+		field, _ := filterLookupField(lookup)
+		value, ok := record[field]
+		if !ok {
+			values = append(values, fmt.Sprintf("%q is not set", field))
+			continue
+		}
+		values = append(values, fmt.Sprintf("%q is %s", field, formatFilterValue(value)))
+	}
+	if len(values) > 0 {
+		line += " (" + strings.Join(values, ", ") + ")"
+	}
+
+	return line
+}
+
+func formatFilterValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluateFilter(t *testing.T) {
+	record := filterRecord{
+		"seller":  "lazy_cat_4252",
+		"amount":  "12.5",
+		"active":  "true",
+		"country": "GB",
+	}
+
+	tests := []struct {
+		filter string
+		match  bool
+	}{
+		{`lookup("seller") == "lazy_cat_4252"`, true},
+		{`lookup("seller") != "lazy_cat_4252"`, false},
+		{`lookup("amount") > 10`, true},
+		{`lookup("amount") == 12.5`, true},
+		{`lookup("amount") < 2`, false},
+		{`lookup("country") < "US"`, true},
+		{`lookup("active") == true`, true},
+		{`lookup("active")`, true},
+		{`lookup("missing") == null`, true},
+		{`lookup("missing") > 1`, false},
+		{`lookup("seller") > 1`, false},
+		{`!(lookup("country") == "GB") || lookup("amount") >= 12.5`, true},
+		{`string_starts_with(lookup("seller"), "lazy") && string_ends_with(lookup("seller"), "4252")`, true},
+		{`string_contains(lookup("missing"), "a")`, false},
+		{`substring(lookup("seller"), 0, 4) == "lazy"`, true},
+		{`substring(lookup("seller"), 5, 100) == "cat_4252"`, true},
+	}
+
+	for _, test := range tests {
+		expr, err := parseFilter(test.filter)
+		if err != nil {
+			t.Errorf("parseFilter(%q) returned unexpected error: %s", test.filter, err)
+			continue
+		}
+
+		match, err := evaluateFilter(expr, record)
+		if err != nil {
+			t.Errorf("evaluateFilter(%q) returned unexpected error: %s", test.filter, err)
+			continue
+		}
+
+		if match != test.match {
+			t.Errorf("evaluateFilter(%q) = %t, expected %t", test.filter, match, test.match)
+		}
+	}
+
+	expr, err := parseFilter(`lookup("seller") && true`)
+	if err != nil {
+		t.Fatalf("parseFilter returned unexpected error: %s", err)
+	}
+	if _, err := evaluateFilter(expr, record); err == nil {
+		t.Errorf("evaluateFilter expected an error using a non boolean record value as a condition")
+	}
+
+	for _, filter := range []string{`foo()`, `foo(lookup("seller"))`, `foo(lookup("seller"), "a", "b") == 1`} {
+		expr, err := parseFilter(filter)
+		if err != nil {
+			t.Fatalf("parseFilter(%q) returned unexpected error: %s", filter, err)
+		}
+		if _, err := evaluateFilter(expr, record); err == nil || !strings.Contains(err.Error(), `unknown function "foo"`) {
+			t.Errorf("evaluateFilter(%q) returned %v, expected the unknown function to be reported", filter, err)
+		}
+	}
+}
+
+func TestExplainFilter(t *testing.T) {
+	expr, err := parseFilter(`lookup("seller") == "someone_else" && !(lookup("amount") > 100)`)
+	if err != nil {
+		t.Fatalf("parseFilter returned unexpected error: %s", err)
+	}
+
+	explanation := strings.Join(explainFilter(expr, filterRecord{"seller": "lazy_cat_4252"}), "\n")
+	expected := `lookup("seller") == "someone_else" => false ("seller" is "lazy_cat_4252")` + "\n" +
+		`lookup("amount") > 100 => false ("amount" is not set)` + "\n" +
+		`!(lookup("amount") > 100) => true ("amount" is not set)`
+
+	if explanation != expected {
+		t.Errorf("explainFilter returned:\n%s\nexpected:\n%s", explanation, expected)
+	}
+}
+
+func TestExplainFilterNegation(t *testing.T) {
+	expr, err := parseFilter(`!(lookup("a") == lookup("b"))`)
+	if err != nil {
+		t.Fatalf("parseFilter returned unexpected error: %s", err)
+	}
+
+	explanation := strings.Join(explainFilter(expr, filterRecord{"a": "x", "b": "x"}), "\n")
+	expected := `lookup("a") == lookup("b") => true ("a" is "x", "b" is "x")` + "\n" +
+		`!(lookup("a") == lookup("b")) => false ("a" is "x", "b" is "x")`
+
+	if explanation != expected {
+		t.Errorf("explainFilter returned:\n%s\nexpected:\n%s", explanation, expected)
+	}
+}
@@ -0,0 +1,254 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// The ambar_filter expression block is a structured alternative to writing filter_contents by hand. Terraform schemas
+// cannot be recursive, so the and / or / not blocks are nested up to filterExpressionMaxDepth levels deep, after which
+// only comparison blocks are available.
+
+const filterExpressionMaxDepth = 3
+
+// filterExpressionOperators maps the operators of a comparison block to the filter function used to render them.
+// Operators not listed here are rendered as a binary comparison against lookup(field).
+var filterExpressionOperators = map[string]string{
+	"contains":    "string_contains",
+	"starts_with": "string_starts_with",
+	"ends_with":   "string_ends_with",
+}
+
+// filterExpressionSchema returns the schema for the top level expression block.
+func filterExpressionSchema() schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "A structured filter, rendered to Ambar Filter syntax by the provider. Conflicts with `filter_contents`. Must contain exactly one `comparison`, `and`, `or` or `not` block.",
+		Description:         "A structured filter, rendered to Ambar Filter syntax by the provider. Conflicts with filter_contents. Must contain exactly one comparison, and, or or not block.",
+		Blocks:              filterExpressionBlocks(filterExpressionMaxDepth),
+	}
+}
+
+func filterExpressionBlocks(depth int) map[string]schema.Block {
+	blocks := map[string]schema.Block{
+		"comparison": schema.ListNestedBlock{
+			MarkdownDescription: "Compares the value of a DataSource column. `operator` is one of `==`, `!=`, `<`, `<=`, `>`, `>=`, `contains`, `starts_with` or `ends_with`.",
+			Description:         "Compares the value of a DataSource column. operator is one of ==, !=, <, <=, >, >=, contains, starts_with or ends_with.",
+			NestedObject: schema.NestedBlockObject{
+				Attributes: map[string]schema.Attribute{
+					"field": schema.StringAttribute{
+						MarkdownDescription: "The DataSource column to compare.",
+						Description:         "The DataSource column to compare.",
+						Required:            true,
+					},
+					"operator": schema.StringAttribute{
+						MarkdownDescription: "The comparison operator.",
+						Description:         "The comparison operator.",
+						Required:            true,
+					},
+					"value": schema.StringAttribute{
+						MarkdownDescription: "The value to compare against. When omitted the column is compared with `null`, which is only supported by the `==` and `!=` operators.",
+						Description:         "The value to compare against. When omitted the column is compared with null, which is only supported by the == and != operators.",
+						Optional:            true,
+					},
+					"value_type": schema.StringAttribute{
+						MarkdownDescription: "How `value` should be interpreted, one of `string`, `number` or `bool`. Defaults to `string`.",
+						Description:         "How value should be interpreted, one of string, number or bool. Defaults to string.",
+						Optional:            true,
+					},
+				},
+			},
+		},
+	}
+
+	if depth == 0 {
+		return blocks
+	}
+
+	for _, name := range []string{"and", "or", "not"} {
+		var description string
+		switch name {
+		case "and":
+			description = "Matches when all of the nested conditions match."
+		case "or":
+			description = "Matches when any of the nested conditions match."
+		case "not":
+			description = "Matches when the single nested condition does not match."
+		}
+
+		blocks[name] = schema.ListNestedBlock{
+			MarkdownDescription: description,
+			Description:         description,
+			NestedObject: schema.NestedBlockObject{
+				Blocks: filterExpressionBlocks(depth - 1),
+			},
+		}
+	}
+
+	return blocks
+}
+
+// filterExpressionFromBlock converts the expression block into an expression tree. The returned bool is false when
+// some part of the block is not yet known, in which case the filter can only be rendered during apply.
+func filterExpressionFromBlock(block types.Object, blockPath path.Path) (filterExpr, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if block.IsUnknown() {
+		return nil, false, diags
+	}
+
+	children, known, childDiags := filterExpressionChildren(block.Attributes(), blockPath)
+	diags.Append(childDiags...)
+	if diags.HasError() || !known {
+		return nil, known, diags
+	}
+
+	if len(children) != 1 {
+		diags.AddAttributeError(blockPath, "Invalid Filter expression",
+			fmt.Sprintf("The expression block must contain exactly one comparison, and, or or not block, got %d.", len(children)))
+		return nil, true, diags
+	}
+
+	return children[0], true, diags
+}
+
+// filterExpressionChildren converts the nested blocks of an expression, and, or or not block. Children are returned
+// in a fixed order by block type, so the rendered filter does not depend on how the blocks are laid out.
+func filterExpressionChildren(attributes map[string]attr.Value, blockPath path.Path) ([]filterExpr, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var children []filterExpr
+
+	for _, name := range []string{"comparison", "and", "or", "not"} {
+		list, ok := attributes[name].(types.List)
+		if !ok || list.IsNull() {
+			continue
+		}
+		if list.IsUnknown() {
+			return nil, false, diags
+		}
+
+		for i, element := range list.Elements() {
+			object, ok := element.(types.Object)
+			if !ok {
+				continue
+			}
+			if object.IsUnknown() {
+				return nil, false, diags
+			}
+
+			elementPath := blockPath.AtName(name).AtListIndex(i)
+
+			if name == "comparison" {
+				child, known, childDiags := filterComparisonFromBlock(object.Attributes(), elementPath)
+				diags.Append(childDiags...)
+				if !known || diags.HasError() {
+					return nil, known, diags
+				}
+				children = append(children, child)
+				continue
+			}
+
+			nested, known, nestedDiags := filterExpressionChildren(object.Attributes(), elementPath)
+			diags.Append(nestedDiags...)
+			if !known || diags.HasError() {
+				return nil, known, diags
+			}
+
+			switch {
+			case len(nested) == 0:
+				diags.AddAttributeError(elementPath, "Invalid Filter expression",
+					fmt.Sprintf("The %s block must contain at least one comparison, and, or or not block.", name))
+				return nil, true, diags
+			case name == "not" && len(nested) != 1:
+				diags.AddAttributeError(elementPath, "Invalid Filter expression",
+					fmt.Sprintf("The not block must contain exactly one comparison, and, or or not block, got %d.", len(nested)))
+				return nil, true, diags
+			case name == "not":
+				children = append(children, &filterUnary{Op: "!", Operand: nested[0]})
+			default:
+				op := "&&"
+				if name == "or" {
+					op = "||"
+				}
+				combined := nested[0]
+				for _, next := range nested[1:] {
+					combined = &filterBinary{Op: op, Left: combined, Right: next}
+				}
+				children = append(children, combined)
+			}
+		}
+	}
+
+	return children, true, diags
+}
+
+func filterComparisonFromBlock(attributes map[string]attr.Value, blockPath path.Path) (filterExpr, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	field, _ := attributes["field"].(types.String)
+	operator, _ := attributes["operator"].(types.String)
+	value, _ := attributes["value"].(types.String)
+	valueType, _ := attributes["value_type"].(types.String)
+
+	if field.IsUnknown() || operator.IsUnknown() || value.IsUnknown() || valueType.IsUnknown() {
+		return nil, false, diags
+	}
+
+	lookup := &filterCall{Name: "lookup", Args: []filterExpr{&filterLiteral{Kind: filterKindString, Value: field.ValueString()}}}
+
+	var literal *filterLiteral
+	switch {
+	case value.IsNull():
+		literal = &filterLiteral{Kind: filterKindNull}
+	case valueType.IsNull() || valueType.ValueString() == "string":
+		literal = &filterLiteral{Kind: filterKindString, Value: value.ValueString()}
+	case valueType.ValueString() == "number":
+		number, err := strconv.ParseFloat(value.ValueString(), 64)
+		if err != nil {
+			diags.AddAttributeError(blockPath.AtName("value"), "Invalid Filter expression",
+				fmt.Sprintf("The value %q is not a valid number.", value.ValueString()))
+			return nil, true, diags
+		}
+		literal = &filterLiteral{Kind: filterKindNumber, Value: number}
+	case valueType.ValueString() == "bool":
+		boolean, err := strconv.ParseBool(value.ValueString())
+		if err != nil {
+			diags.AddAttributeError(blockPath.AtName("value"), "Invalid Filter expression",
+				fmt.Sprintf("The value %q is not a valid bool, expected true or false.", value.ValueString()))
+			return nil, true, diags
+		}
+		literal = &filterLiteral{Kind: filterKindBool, Value: boolean}
+	default:
+		diags.AddAttributeError(blockPath.AtName("value_type"), "Invalid Filter expression",
+			fmt.Sprintf("The value_type %q is not supported, expected one of string, number or bool.", valueType.ValueString()))
+		return nil, true, diags
+	}
+
+	var expr filterExpr
+	switch op := operator.ValueString(); op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		expr = &filterBinary{Op: op, Left: lookup, Right: literal}
+	default:
+		function, ok := filterExpressionOperators[op]
+		if !ok {
+			diags.AddAttributeError(blockPath.AtName("operator"), "Invalid Filter expression",
+				fmt.Sprintf("The operator %q is not supported, expected one of ==, !=, <, <=, >, >=, contains, starts_with or ends_with.", op))
+			return nil, true, diags
+		}
+		expr = &filterCall{Name: function, Args: []filterExpr{lookup, literal}}
+	}
+
+	// Let the filter type checker report invalid combinations, such as ordering against a bool or null.
+	if _, err := checkFilterExpr(expr); err != nil {
+		diags.AddAttributeError(blockPath, "Invalid Filter expression",
+			fmt.Sprintf("The comparison on field %q is not valid: %s.", field.ValueString(), filterErrorMessage(err)))
+		return nil, true, diags
+	}
+
+	return expr, true, diags
+}
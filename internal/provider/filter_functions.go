@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Provider defined functions for working with Ambar filter statements. These use the same parser, renderer and
+// evaluator as the ambar_filter resource, so a filter built or tested with them behaves the same way once applied.
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &filterMatchesFunction{}
+var _ function.Function = &filterCombineFunction{}
+var _ function.Function = &filterValidateFunction{}
+var _ function.Function = &filterValidateErrorFunction{}
+
+func NewFilterMatchesFunction() function.Function {
+	return &filterMatchesFunction{}
+}
+
+// filterMatchesFunction evaluates a filter against a sample record.
+type filterMatchesFunction struct{}
+
+func (f *filterMatchesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "filter_matches"
+}
+
+func (f *filterMatchesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Evaluate an Ambar Filter against a record",
+		MarkdownDescription: "Returns whether the Ambar Filter statement matches a sample record, given as a map of DataSource column names to values. Uses the same evaluation rules as the `test_case` block of `ambar_filter`.",
+		Description:         "Returns whether the Ambar Filter statement matches a sample record, given as a map of DataSource column names to values. Uses the same evaluation rules as the test_case block of ambar_filter.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "filter",
+				MarkdownDescription: "A filter statement using Ambar Filter syntax.",
+				Description:         "A filter statement using Ambar Filter syntax.",
+			},
+			function.MapParameter{
+				Name:                "record",
+				ElementType:         types.StringType,
+				MarkdownDescription: "The sample record to evaluate the filter against.",
+				Description:         "The sample record to evaluate the filter against.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *filterMatchesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var filter string
+	var record filterRecord
+
+	resp.Error = req.Arguments.Get(ctx, &filter, &record)
+	if resp.Error != nil {
+		return
+	}
+
+	expr, err := parseFilter(filter)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Invalid Filter syntax: "+err.Error())
+		return
+	}
+
+	// Functions which are not known locally pass validation, but can't be evaluated.
+	if calls := filterUnknownFunctions(expr); len(calls) > 0 {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("The Filter calls the function %q at %s, which this version of the provider can not evaluate.", calls[0].Name, calls[0].Pos))
+		return
+	}
+
+	matched, err := evaluateFilter(expr, record)
+	if err != nil {
+		resp.Error = function.NewFuncError("Unable to evaluate Filter: " + err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, matched)
+}
+
+func NewFilterAndFunction() function.Function {
+	return &filterCombineFunction{name: "filter_and", op: "&&"}
+}
+
+func NewFilterOrFunction() function.Function {
+	return &filterCombineFunction{name: "filter_or", op: "||"}
+}
+
+// filterCombineFunction joins a list of filters using a logical operator.
+type filterCombineFunction struct {
+	name string
+	op   string
+}
+
+func (f *filterCombineFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = f.name
+}
+
+func (f *filterCombineFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	matches := "all"
+	if f.op == "||" {
+		matches = "any"
+	}
+
+	resp.Definition = function.Definition{
+		Summary:             fmt.Sprintf("Combine Ambar Filters with %s", f.op),
+		MarkdownDescription: fmt.Sprintf("Returns a single Ambar Filter statement which matches when %s of the given filters match. Each filter is validated, and the result is rendered in the same normalized form used for the `expression` block of `ambar_filter`.", matches),
+		Description:         fmt.Sprintf("Returns a single Ambar Filter statement which matches when %s of the given filters match. Each filter is validated, and the result is rendered in the same normalized form used for the expression block of ambar_filter.", matches),
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:                "filters",
+				ElementType:         types.StringType,
+				MarkdownDescription: "The filter statements to combine. Must contain at least one filter.",
+				Description:         "The filter statements to combine. Must contain at least one filter.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *filterCombineFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var filters []string
+
+	resp.Error = req.Arguments.Get(ctx, &filters)
+	if resp.Error != nil {
+		return
+	}
+
+	if len(filters) == 0 {
+		resp.Error = function.NewArgumentFuncError(0, "At least one filter must be given.")
+		return
+	}
+
+	var combined filterExpr
+	for i, filter := range filters {
+		expr, err := parseFilter(filter)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Invalid Filter syntax in element %d: %s", i, err.Error()))
+			return
+		}
+
+		if combined == nil {
+			combined = expr
+			continue
+		}
+		combined = &filterBinary{Op: f.op, Left: combined, Right: expr}
+	}
+
+	resp.Error = resp.Result.Set(ctx, renderFilter(combined))
+}
+
+func NewFilterValidateFunction() function.Function {
+	return &filterValidateFunction{}
+}
+
+// filterValidateFunction checks a filter statement for syntax errors.
+type filterValidateFunction struct{}
+
+func (f *filterValidateFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "filter_validate"
+}
+
+func (f *filterValidateFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Check an Ambar Filter for syntax errors",
+		MarkdownDescription: "Returns whether the string is a valid Ambar Filter statement, using the same validation as `ambar_filter.filter_contents`.",
+		Description:         "Returns whether the string is a valid Ambar Filter statement, using the same validation as ambar_filter.filter_contents.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "filter",
+				MarkdownDescription: "The filter statement to validate.",
+				Description:         "The filter statement to validate.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *filterValidateFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var filter string
+
+	resp.Error = req.Arguments.Get(ctx, &filter)
+	if resp.Error != nil {
+		return
+	}
+
+	_, err := parseFilter(filter)
+	resp.Error = resp.Result.Set(ctx, err == nil)
+}
+
+func NewFilterValidateErrorFunction() function.Function {
+	return &filterValidateErrorFunction{}
+}
+
+// filterValidateErrorFunction describes the syntax error of a filter statement, if any.
+type filterValidateErrorFunction struct{}
+
+func (f *filterValidateErrorFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "filter_validate_error"
+}
+
+func (f *filterValidateErrorFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Describe the syntax error of an Ambar Filter",
+		MarkdownDescription: "Returns the syntax error of an Ambar Filter statement with its line and column, or an empty string when the filter is valid. Uses the same validation as `ambar_filter.filter_contents` and `filter_validate`.",
+		Description:         "Returns the syntax error of an Ambar Filter statement with its line and column, or an empty string when the filter is valid. Uses the same validation as ambar_filter.filter_contents and filter_validate.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "filter",
+				MarkdownDescription: "The filter statement to validate.",
+				Description:         "The filter statement to validate.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *filterValidateErrorFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var filter string
+
+	resp.Error = req.Arguments.Get(ctx, &filter)
+	if resp.Error != nil {
+		return
+	}
+
+	var message string
+	if _, err := parseFilter(filter); err != nil {
+		message = err.Error()
+	}
+	resp.Error = resp.Result.Set(ctx, message)
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// runFilterFunction calls a provider defined function directly, without going through Terraform.
+func runFilterFunction(t *testing.T, f function.Function, args ...attr.Value) (attr.Value, *function.FuncError) {
+	t.Helper()
+
+	var definition function.DefinitionResponse
+	f.Definition(context.Background(), function.DefinitionRequest{}, &definition)
+
+	result, funcErr := definition.Definition.Return.NewResultData(context.Background())
+	if funcErr != nil {
+		t.Fatalf("unable to create function result: %s", funcErr)
+	}
+
+	resp := function.RunResponse{Result: result}
+	f.Run(context.Background(), function.RunRequest{Arguments: function.NewArgumentsData(args)}, &resp)
+
+	return resp.Result.Value(), resp.Error
+}
+
+func TestFilterCombineFunctions(t *testing.T) {
+	filters := types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue(`lookup("a") == "x" || lookup("b") == "y"`),
+		types.StringValue(`lookup("c")==1`),
+	})
+
+	result, err := runFilterFunction(t, NewFilterAndFunction(), filters)
+	if err != nil {
+		t.Fatalf("filter_and returned unexpected error: %s", err)
+	}
+	if expected := types.StringValue(`(lookup("a") == "x" || lookup("b") == "y") && lookup("c") == 1`); !result.Equal(expected) {
+		t.Errorf("filter_and returned %s, expected %s", result, expected)
+	}
+
+	result, err = runFilterFunction(t, NewFilterOrFunction(), filters)
+	if err != nil {
+		t.Fatalf("filter_or returned unexpected error: %s", err)
+	}
+	if expected := types.StringValue(`lookup("a") == "x" || lookup("b") == "y" || lookup("c") == 1`); !result.Equal(expected) {
+		t.Errorf("filter_or returned %s, expected %s", result, expected)
+	}
+
+	invalid := types.ListValueMust(types.StringType, []attr.Value{types.StringValue(`lookup("a") ==`)})
+	if _, err := runFilterFunction(t, NewFilterAndFunction(), invalid); err == nil {
+		t.Errorf("filter_and expected an error for an invalid filter, got none")
+	}
+}
+
+func TestFilterMatchesFunction(t *testing.T) {
+	record := types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue("x")})
+
+	result, err := runFilterFunction(t, NewFilterMatchesFunction(), types.StringValue(`lookup("a") == "x"`), record)
+	if err != nil {
+		t.Fatalf("filter_matches returned unexpected error: %s", err)
+	}
+	if !result.Equal(types.BoolValue(true)) {
+		t.Errorf("filter_matches returned %s, expected true", result)
+	}
+
+	result, err = runFilterFunction(t, NewFilterValidateFunction(), types.StringValue(`lookup(a)`))
+	if err != nil {
+		t.Fatalf("filter_validate returned unexpected error: %s", err)
+	}
+	if !result.Equal(types.BoolValue(false)) {
+		t.Errorf("filter_validate returned %s, expected false", result)
+	}
+}
+
+func TestFilterMatchesFunctionUnknownFunction(t *testing.T) {
+	record := types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue("x")})
+
+	for _, filter := range []string{`foo()`, `foo(lookup("a"))`} {
+		_, err := runFilterFunction(t, NewFilterMatchesFunction(), types.StringValue(filter), record)
+		if err == nil || !strings.Contains(err.Error(), `function "foo"`) {
+			t.Errorf("filter_matches(%q) returned %v, expected the unknown function to be reported", filter, err)
+		}
+	}
+}
+
+func TestFilterValidateErrorFunction(t *testing.T) {
+	result, err := runFilterFunction(t, NewFilterValidateErrorFunction(), types.StringValue("lookup(\"a\") == \"x\" &&\n  lookup(a)"))
+	if err != nil {
+		t.Fatalf("filter_validate_error returned unexpected error: %s", err)
+	}
+	if message, ok := result.(types.String); !ok || !strings.Contains(message.ValueString(), "line 2, column") {
+		t.Errorf("filter_validate_error returned %s, expected the error with its position", result)
+	}
+
+	result, err = runFilterFunction(t, NewFilterValidateErrorFunction(), types.StringValue(`lookup("a") == "x"`))
+	if err != nil {
+		t.Fatalf("filter_validate_error returned unexpected error: %s", err)
+	}
+	if !result.Equal(types.StringValue("")) {
+		t.Errorf("filter_validate_error returned %s, expected an empty string", result)
+	}
+}
@@ -0,0 +1,716 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file contains a small parser for the Ambar filter language. Filters are sent to Ambar as an opaque base64
+// string, so without a local parser any syntax error is only reported after a round trip to the Ambar API. Parsing
+// the filter locally lets us report errors during validation, with a line and column pointing at the problem.
+//
+// The grammar is as follows, with the usual precedence of ! over && over ||:
+//
+//	expression := or
+//	or         := and ( "||" and )*
+//	and        := unary ( "&&" unary )*
+//	unary      := "!" unary | comparison
+//	comparison := primary ( ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) primary )?
+//	primary    := "(" expression ")" | call | string | number | "true" | "false" | "null"
+//	call       := identifier "(" ( expression ( "," expression )* )? ")"
+//
+// The operators, functions and literals follow the filter syntax in Ambar's documentation at https://docs.ambar.cloud.
+// Note ! binds looser than the comparison operators, so !lookup("a") == "x" is !(lookup("a") == "x"). A lookup is
+// a column value rather than a boolean, so negating it before the comparison would never be meaningful, and binding
+// tighter would reject or silently change the meaning of filters written this way.
+
+// encodeFilterContents returns a filter statement in the base64 encoding the Ambar API expects.
+func encodeFilterContents(contents string) string {
+	return base64.StdEncoding.EncodeToString([]byte(contents))
+}
+
+// filterPosition is a 1 based line and column within a filter statement.
+type filterPosition struct {
+	Line   int
+	Column int
+}
+
+func (p filterPosition) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// filterSyntaxError describes a problem found while parsing or checking a filter statement.
+type filterSyntaxError struct {
+	Pos     filterPosition
+	Message string
+}
+
+func (e *filterSyntaxError) Error() string {
+	return e.Pos.String() + ": " + e.Message
+}
+
+// filterErrorMessage returns the message of a filter error without its position, for errors about expressions which
+// were not written by hand and so have no meaningful position.
+func filterErrorMessage(err error) string {
+	var syntaxErr *filterSyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Message
+	}
+	return err.Error()
+}
+
+// filterKind is the static type of a filter expression.
+type filterKind int
+
+const (
+	// filterKindAny is used for record values, which are only known once a record is being filtered.
+	filterKindAny filterKind = iota
+	filterKindString
+	filterKindNumber
+	filterKindBool
+	filterKindNull
+)
+
+func (k filterKind) String() string {
+	switch k {
+	case filterKindString:
+		return "string"
+	case filterKindNumber:
+		return "number"
+	case filterKindBool:
+		return "boolean"
+	case filterKindNull:
+		return "null"
+	default:
+		return "record value"
+	}
+}
+
+// filterExpr is a node in a parsed filter statement.
+type filterExpr interface {
+	Position() filterPosition
+}
+
+// filterLiteral is a string, number, boolean or null constant. Value holds a string, float64, bool or nil.
+type filterLiteral struct {
+	Pos   filterPosition
+	Kind  filterKind
+	Value any
+}
+
+// filterUnary is the logical negation of its operand.
+type filterUnary struct {
+	Pos     filterPosition
+	Op      string
+	Operand filterExpr
+}
+
+// filterBinary is a comparison or logical operation.
+type filterBinary struct {
+	Pos   filterPosition
+	Op    string
+	Left  filterExpr
+	Right filterExpr
+}
+
+// filterCall is a call to one of the built-in filter functions, such as lookup("column").
+type filterCall struct {
+	Pos  filterPosition
+	Name string
+	Args []filterExpr
+}
+
+func (e *filterLiteral) Position() filterPosition { return e.Pos }
+func (e *filterUnary) Position() filterPosition   { return e.Pos }
+func (e *filterBinary) Position() filterPosition  { return e.Pos }
+func (e *filterCall) Position() filterPosition    { return e.Pos }
+
+// filterFunction describes the signature of a built-in filter function.
+type filterFunction struct {
+	Params []filterKind
+	Result filterKind
+}
+
+// filterFunctions are the functions available to filter statements. lookup is special cased during checking, as its
+// argument must be a string literal naming a column of the DataSource.
+var filterFunctions = map[string]filterFunction{
+	"lookup":             {Params: []filterKind{filterKindString}, Result: filterKindAny},
+	"substring":          {Params: []filterKind{filterKindString, filterKindNumber, filterKindNumber}, Result: filterKindString},
+	"string_contains":    {Params: []filterKind{filterKindString, filterKindString}, Result: filterKindBool},
+	"string_starts_with": {Params: []filterKind{filterKindString, filterKindString}, Result: filterKindBool},
+	"string_ends_with":   {Params: []filterKind{filterKindString, filterKindString}, Result: filterKindBool},
+}
+
+// parseFilter parses and type checks a filter statement, returning the root of the expression tree.
+func parseFilter(contents string) (filterExpr, error) {
+	tokens, err := lexFilter(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	if p.peek().Kind == filterTokenEOF {
+		return nil, &filterSyntaxError{Pos: p.peek().Pos, Message: "filter statement is empty"}
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.Kind != filterTokenEOF {
+		return nil, &filterSyntaxError{Pos: tok.Pos, Message: fmt.Sprintf("unexpected %s after end of expression", tok)}
+	}
+
+	kind, err := checkFilterExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind != filterKindBool && kind != filterKindAny {
+		return nil, &filterSyntaxError{Pos: expr.Position(), Message: fmt.Sprintf("filter must evaluate to a boolean, got %s", kind)}
+	}
+
+	return expr, nil
+}
+
+// checkFilterExpr validates operand and argument types, returning the kind of value the expression produces.
+func checkFilterExpr(expr filterExpr) (filterKind, error) {
+	switch e := expr.(type) {
+	case *filterLiteral:
+		return e.Kind, nil
+
+	case *filterUnary:
+		kind, err := checkFilterExpr(e.Operand)
+		if err != nil {
+			return kind, err
+		}
+		if kind != filterKindBool && kind != filterKindAny {
+			return kind, &filterSyntaxError{Pos: e.Operand.Position(), Message: fmt.Sprintf("operator %q expects a boolean, got %s", e.Op, kind)}
+		}
+		return filterKindBool, nil
+
+	case *filterBinary:
+		left, err := checkFilterExpr(e.Left)
+		if err != nil {
+			return left, err
+		}
+		right, err := checkFilterExpr(e.Right)
+		if err != nil {
+			return right, err
+		}
+
+		switch e.Op {
+		case "&&", "||":
+			for _, operand := range []struct {
+				expr filterExpr
+				kind filterKind
+			}{{e.Left, left}, {e.Right, right}} {
+				if operand.kind != filterKindBool && operand.kind != filterKindAny {
+					return operand.kind, &filterSyntaxError{Pos: operand.expr.Position(), Message: fmt.Sprintf("operator %q expects a boolean, got %s", e.Op, operand.kind)}
+				}
+			}
+		case "<", "<=", ">", ">=":
+			for _, operand := range []struct {
+				expr filterExpr
+				kind filterKind
+			}{{e.Left, left}, {e.Right, right}} {
+				if operand.kind == filterKindBool || operand.kind == filterKindNull {
+					return operand.kind, &filterSyntaxError{Pos: operand.expr.Position(), Message: fmt.Sprintf("operator %q cannot compare a %s", e.Op, operand.kind)}
+				}
+			}
+			if left != filterKindAny && right != filterKindAny && left != right {
+				return left, &filterSyntaxError{Pos: e.Pos, Message: fmt.Sprintf("operator %q cannot compare a %s with a %s", e.Op, left, right)}
+			}
+		}
+		return filterKindBool, nil
+
+	case *filterCall:
+		function, ok := filterFunctions[e.Name]
+		if !ok {
+			// Ambar may support functions added after this version of the provider, so only the arguments of unknown
+			// functions are checked, and the call is left for Ambar to validate.
+			for _, arg := range e.Args {
+				if kind, err := checkFilterExpr(arg); err != nil {
+					return kind, err
+				}
+			}
+			return filterKindAny, nil
+		}
+		if len(e.Args) != len(function.Params) {
+			return filterKindAny, &filterSyntaxError{Pos: e.Pos, Message: fmt.Sprintf("function %q expects %d argument(s), got %d", e.Name, len(function.Params), len(e.Args))}
+		}
+		if e.Name == "lookup" {
+			if _, ok := filterLookupField(e); !ok {
+				return filterKindAny, &filterSyntaxError{Pos: e.Args[0].Position(), Message: "lookup expects a string literal naming a column"}
+			}
+			return function.Result, nil
+		}
+		for i, arg := range e.Args {
+			kind, err := checkFilterExpr(arg)
+			if err != nil {
+				return kind, err
+			}
+			if kind != function.Params[i] && kind != filterKindAny {
+				return kind, &filterSyntaxError{Pos: arg.Position(), Message: fmt.Sprintf("argument %d of %q must be a %s, got %s", i+1, e.Name, function.Params[i], kind)}
+			}
+		}
+		return function.Result, nil
+	}
+
+	return filterKindAny, &filterSyntaxError{Pos: expr.Position(), Message: "unsupported expression"}
+}
+
+// filterLookupField returns the column name passed to a lookup call.
+func filterLookupField(call *filterCall) (string, bool) {
+	if call.Name != "lookup" || len(call.Args) != 1 {
+		return "", false
+	}
+	literal, ok := call.Args[0].(*filterLiteral)
+	if !ok || literal.Kind != filterKindString {
+		return "", false
+	}
+	field, ok := literal.Value.(string)
+	return field, ok
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenEOF filterTokenKind = iota
+	filterTokenIdent
+	filterTokenString
+	filterTokenNumber
+	filterTokenOperator
+)
+
+type filterToken struct {
+	Kind filterTokenKind
+	Text string
+	// Value holds the unquoted contents of a string token, or the parsed value of a number token.
+	Value any
+	Pos   filterPosition
+}
+
+func (t filterToken) String() string {
+	switch t.Kind {
+	case filterTokenEOF:
+		return "end of filter"
+	case filterTokenString:
+		return "string " + t.Text
+	case filterTokenNumber:
+		return "number " + t.Text
+	default:
+		return strconv.Quote(t.Text)
+	}
+}
+
+// filterOperators are ordered so that two character operators are matched before their one character prefixes.
+var filterOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", ","}
+
+func lexFilter(contents string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(contents)
+	pos := filterPosition{Line: 1, Column: 1}
+
+	advance := func(n int) {
+		for i := 0; i < n; i++ {
+			if runes[0] == '\n' {
+				pos.Line++
+				pos.Column = 1
+			} else {
+				pos.Column++
+			}
+			runes = runes[1:]
+		}
+	}
+
+	for len(runes) > 0 {
+		r := runes[0]
+		start := pos
+
+		switch {
+		case unicode.IsSpace(r):
+			advance(1)
+
+		case r == '"':
+			value, length, err := lexFilterString(runes, start)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filterToken{Kind: filterTokenString, Text: string(runes[:length]), Value: value, Pos: start})
+			advance(length)
+
+		case unicode.IsDigit(r) || (r == '-' && len(runes) > 1 && unicode.IsDigit(runes[1])):
+			length := 1
+			for length < len(runes) && (unicode.IsDigit(runes[length]) || runes[length] == '.') {
+				length++
+			}
+			text := string(runes[:length])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &filterSyntaxError{Pos: start, Message: fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, filterToken{Kind: filterTokenNumber, Text: text, Value: value, Pos: start})
+			advance(length)
+
+		case unicode.IsLetter(r) || r == '_':
+			length := 1
+			for length < len(runes) && (unicode.IsLetter(runes[length]) || unicode.IsDigit(runes[length]) || runes[length] == '_') {
+				length++
+			}
+			tokens = append(tokens, filterToken{Kind: filterTokenIdent, Text: string(runes[:length]), Pos: start})
+			advance(length)
+
+		default:
+			matched := false
+			for _, op := range filterOperators {
+				if strings.HasPrefix(string(runes[:min(len(runes), len(op))]), op) {
+					tokens = append(tokens, filterToken{Kind: filterTokenOperator, Text: op, Pos: start})
+					advance(len(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &filterSyntaxError{Pos: start, Message: fmt.Sprintf("unexpected character %q", r)}
+			}
+		}
+	}
+
+	return append(tokens, filterToken{Kind: filterTokenEOF, Pos: pos}), nil
+}
+
+// lexFilterString reads a double quoted string literal, returning its unescaped value and its length in runes.
+func lexFilterString(runes []rune, start filterPosition) (string, int, error) {
+	var value strings.Builder
+	for i := 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return value.String(), i + 1, nil
+		case '\n':
+			return "", 0, &filterSyntaxError{Pos: start, Message: "unterminated string"}
+		case '\\':
+			i++
+			if i >= len(runes) {
+				return "", 0, &filterSyntaxError{Pos: start, Message: "unterminated string"}
+			}
+			switch runes[i] {
+			case '"', '\\':
+				value.WriteRune(runes[i])
+			case 'n':
+				value.WriteRune('\n')
+			case 't':
+				value.WriteRune('\t')
+			default:
+				return "", 0, &filterSyntaxError{Pos: start, Message: fmt.Sprintf("invalid escape sequence \"\\%c\" in string", runes[i])}
+			}
+		default:
+			value.WriteRune(runes[i])
+		}
+	}
+	return "", 0, &filterSyntaxError{Pos: start, Message: "unterminated string"}
+}
+
+type filterParser struct {
+	tokens []filterToken
+	index  int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.index]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.index]
+	if tok.Kind != filterTokenEOF {
+		p.index++
+	}
+	return tok
+}
+
+func (p *filterParser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.Kind != filterTokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.Text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *filterParser) expect(op string) error {
+	if !p.isOperator(op) {
+		tok := p.peek()
+		return &filterSyntaxError{Pos: tok.Pos, Message: fmt.Sprintf("expected %q, got %s", op, tok)}
+	}
+	p.next()
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		op := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterBinary{Pos: op.Pos, Op: op.Text, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") {
+		op := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterBinary{Pos: op.Pos, Op: op.Text, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.isOperator("!") {
+		op := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterUnary{Pos: op.Pos, Op: op.Text, Operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator("==", "!=", "<", "<=", ">", ">=") {
+		op := p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		if p.isOperator("==", "!=", "<", "<=", ">", ">=") {
+			tok := p.peek()
+			return nil, &filterSyntaxError{Pos: tok.Pos, Message: "comparisons cannot be chained, use parentheses and && instead"}
+		}
+		return &filterBinary{Pos: op.Pos, Op: op.Text, Left: left, Right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *filterParser) parsePrimary() (filterExpr, error) {
+	tok := p.next()
+
+	switch tok.Kind {
+	case filterTokenString:
+		return &filterLiteral{Pos: tok.Pos, Kind: filterKindString, Value: tok.Value}, nil
+
+	case filterTokenNumber:
+		return &filterLiteral{Pos: tok.Pos, Kind: filterKindNumber, Value: tok.Value}, nil
+
+	case filterTokenIdent:
+		switch tok.Text {
+		case "true", "false":
+			return &filterLiteral{Pos: tok.Pos, Kind: filterKindBool, Value: tok.Text == "true"}, nil
+		case "null":
+			return &filterLiteral{Pos: tok.Pos, Kind: filterKindNull}, nil
+		}
+		if !p.isOperator("(") {
+			return nil, &filterSyntaxError{Pos: tok.Pos, Message: fmt.Sprintf("unexpected identifier %q, record fields are read with lookup(%q)", tok.Text, tok.Text)}
+		}
+		p.next()
+		call := &filterCall{Pos: tok.Pos, Name: tok.Text}
+		if p.isOperator(")") {
+			p.next()
+			return call, nil
+		}
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+			if !p.isOperator(",") {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return call, nil
+
+	case filterTokenOperator:
+		if tok.Text == "(" {
+			expr, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		}
+	}
+
+	return nil, &filterSyntaxError{Pos: tok.Pos, Message: fmt.Sprintf("unexpected %s, expected a value", tok)}
+}
+
+// filterLookups returns every lookup call in the expression, in the order they appear in the filter statement.
+func filterLookups(expr filterExpr) []*filterCall {
+	var lookups []*filterCall
+
+	switch e := expr.(type) {
+	case *filterUnary:
+		lookups = append(lookups, filterLookups(e.Operand)...)
+	case *filterBinary:
+		lookups = append(lookups, filterLookups(e.Left)...)
+		lookups = append(lookups, filterLookups(e.Right)...)
+	case *filterCall:
+		if _, ok := filterLookupField(e); ok {
+			lookups = append(lookups, e)
+		}
+		for _, arg := range e.Args {
+			lookups = append(lookups, filterLookups(arg)...)
+		}
+	}
+
+	return lookups
+}
+
+// filterUnknownFunctions returns every call to a function missing from filterFunctions, in the order they appear in
+// the filter statement.
+func filterUnknownFunctions(expr filterExpr) []*filterCall {
+	var calls []*filterCall
+
+	switch e := expr.(type) {
+	case *filterUnary:
+		calls = append(calls, filterUnknownFunctions(e.Operand)...)
+	case *filterBinary:
+		calls = append(calls, filterUnknownFunctions(e.Left)...)
+		calls = append(calls, filterUnknownFunctions(e.Right)...)
+	case *filterCall:
+		if _, ok := filterFunctions[e.Name]; !ok {
+			calls = append(calls, e)
+		}
+		for _, arg := range e.Args {
+			calls = append(calls, filterUnknownFunctions(arg)...)
+		}
+	}
+
+	return calls
+}
+
+// renderFilter formats an expression tree as a filter statement. The output is deterministic, and only adds the
+// parentheses needed to preserve the structure of the tree, so parsing the output gives back an equivalent tree.
+func renderFilter(expr filterExpr) string {
+	switch e := expr.(type) {
+	case *filterLiteral:
+		switch value := e.Value.(type) {
+		case string:
+			return quoteFilterString(value)
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(value)
+		default:
+			return "null"
+		}
+
+	case *filterUnary:
+		return e.Op + renderFilterOperand(e.Operand, filterPrecedence(e))
+
+	case *filterBinary:
+		precedence := filterPrecedence(e)
+		// Comparisons cannot be chained, so their operands need parentheses around anything but a primary.
+		if precedence == filterPrecedenceComparison {
+			precedence++
+		}
+		return renderFilterOperand(e.Left, precedence) + " " + e.Op + " " + renderFilterOperand(e.Right, precedence)
+
+	case *filterCall:
+		args := make([]string, 0, len(e.Args))
+		for _, arg := range e.Args {
+			args = append(args, renderFilter(arg))
+		}
+		return e.Name + "(" + strings.Join(args, ", ") + ")"
+	}
+
+	return ""
+}
+
+const (
+	filterPrecedenceOr = iota
+	filterPrecedenceAnd
+	filterPrecedenceUnary
+	filterPrecedenceComparison
+	filterPrecedencePrimary
+)
+
+func filterPrecedence(expr filterExpr) int {
+	switch e := expr.(type) {
+	case *filterBinary:
+		switch e.Op {
+		case "||":
+			return filterPrecedenceOr
+		case "&&":
+			return filterPrecedenceAnd
+		default:
+			return filterPrecedenceComparison
+		}
+	case *filterUnary:
+		return filterPrecedenceUnary
+	default:
+		return filterPrecedencePrimary
+	}
+}
+
+// renderFilterOperand renders an operand, wrapping it in parentheses when it binds less tightly than its parent.
+func renderFilterOperand(expr filterExpr, parentPrecedence int) string {
+	rendered := renderFilter(expr)
+	precedence := filterPrecedence(expr)
+	// Negated comparisons are parenthesized too, they don't need to be but are much easier to read that way.
+	if precedence < parentPrecedence || (parentPrecedence == filterPrecedenceUnary && precedence == filterPrecedenceComparison) {
+		return "(" + rendered + ")"
+	}
+	return rendered
+}
+
+// quoteFilterString quotes a string literal using the escape sequences understood by the filter lexer.
+func quoteFilterString(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// filterSha256 returns the hex encoded SHA256 hash of the normalized filter statement. Statements which cannot be
+// parsed are hashed as they are.
+func filterSha256(contents string) string {
+	if expr, err := parseFilter(contents); err == nil {
+		contents = renderFilter(expr)
+	}
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	valid := []string{
+		`lookup("seller_username") == "lazy_cat_4252"`,
+		`lookup("amount") >= 10.5 && !(lookup("currency") == "EUR")`,
+		`string_starts_with(lookup("email"), "admin@") || lookup("deleted") == null`,
+		"lookup(\"a\") == 1 &&\n  lookup(\"b\") != \"two\"",
+		`substring(lookup("id"), 0, 3) == "abc"`,
+		`true`,
+		`lookup("is_active")`,
+		`string_matches_regex(lookup("email"), "^admin@") && lookup("a") == "b"`,
+	}
+
+	for _, filter := range valid {
+		if _, err := parseFilter(filter); err != nil {
+			t.Errorf("parseFilter(%q) returned unexpected error: %s", filter, err)
+		}
+	}
+
+	invalid := []struct {
+		filter string
+		line   int
+		column int
+		error  string
+	}{
+		{``, 1, 1, "filter statement is empty"},
+		{`lookup("a") == `, 1, 16, "expected a value"},
+		{`lookup("a") = "b"`, 1, 13, "unexpected character '='"},
+		{`lookup("a") == "b`, 1, 16, "unterminated string"},
+		{"lookup(\"a\") == \"b\" &&\n  seller == \"c\"", 2, 3, `unexpected identifier "seller"`},
+		{`lookup("a") == "b")`, 1, 19, `unexpected ")" after end of expression`},
+		{`lookup(name)`, 1, 8, "unexpected identifier"},
+		{`lookup(lookup("a"))`, 1, 8, "lookup expects a string literal"},
+		{`contains(lookup(a), "b")`, 1, 17, "unexpected identifier"},
+		{`substring(lookup("a"), 0)`, 1, 1, "expects 3 argument(s), got 2"},
+		{`"a" == "b" && 1`, 1, 15, `operator "&&" expects a boolean, got number`},
+		{`lookup("a") < true`, 1, 15, "cannot compare a boolean"},
+		{`"abc"`, 1, 1, "filter must evaluate to a boolean, got string"},
+		{`lookup("a") == 1 == 2`, 1, 18, "comparisons cannot be chained"},
+	}
+
+	for _, test := range invalid {
+		_, err := parseFilter(test.filter)
+		if err == nil {
+			t.Errorf("parseFilter(%q) expected an error, got none", test.filter)
+			continue
+		}
+
+		syntaxErr, ok := err.(*filterSyntaxError)
+		if !ok {
+			t.Errorf("parseFilter(%q) returned %T, expected *filterSyntaxError", test.filter, err)
+			continue
+		}
+
+		if syntaxErr.Pos.Line != test.line || syntaxErr.Pos.Column != test.column {
+			t.Errorf("parseFilter(%q) reported error at %s, expected line %d, column %d", test.filter, syntaxErr.Pos, test.line, test.column)
+		}
+
+		if !strings.Contains(syntaxErr.Message, test.error) {
+			t.Errorf("parseFilter(%q) returned error %q, expected it to contain %q", test.filter, syntaxErr.Message, test.error)
+		}
+	}
+}
+
+func TestFilterLookups(t *testing.T) {
+	expr, err := parseFilter(`lookup("a") == "x" || (!string_contains(lookup("b"), "y") && substring(lookup("c"), 0, 1) == "z")`)
+	if err != nil {
+		t.Fatalf("parseFilter returned unexpected error: %s", err)
+	}
+
+	var fields []string
+	for _, lookup := range filterLookups(expr) {
+		field, _ := filterLookupField(lookup)
+		fields = append(fields, field)
+	}
+
+	if strings.Join(fields, ",") != "a,b,c" {
+		t.Errorf("filterLookups returned fields %v, expected [a b c]", fields)
+	}
+}
+
+func TestFilterUnknownFunctions(t *testing.T) {
+	expr, err := parseFilter(`string_contains(lookup("a"), "x") || !is_uuid(lower(lookup("b")))`)
+	if err != nil {
+		t.Fatalf("parseFilter returned unexpected error: %s", err)
+	}
+
+	var names []string
+	for _, call := range filterUnknownFunctions(expr) {
+		names = append(names, call.Name+"@"+call.Pos.String())
+	}
+
+	if expected := "is_uuid@line 1, column 39,lower@line 1, column 47"; strings.Join(names, ",") != expected {
+		t.Errorf("filterUnknownFunctions returned %v, expected %s", names, expected)
+	}
+}
+
+func TestParseFilterNotPrecedence(t *testing.T) {
+	// ! applies to the whole comparison, not only to the lookup on its left.
+	expr, err := parseFilter(`!lookup("a") == "x" && lookup("b") == "y"`)
+	if err != nil {
+		t.Fatalf("parseFilter returned unexpected error: %s", err)
+	}
+
+	and, ok := expr.(*filterBinary)
+	if !ok || and.Op != "&&" {
+		t.Fatalf("parseFilter returned %s, expected an && at the top", renderFilter(expr))
+	}
+	not, ok := and.Left.(*filterUnary)
+	if !ok {
+		t.Fatalf("parseFilter returned %s, expected the left of && to be negated", renderFilter(expr))
+	}
+	if comparison, ok := not.Operand.(*filterBinary); !ok || comparison.Op != "==" {
+		t.Errorf("parseFilter returned %s, expected ! to negate the == comparison", renderFilter(expr))
+	}
+
+	matched, err := evaluateFilter(expr, filterRecord{"a": "z", "b": "y"})
+	if err != nil || !matched {
+		t.Errorf("evaluateFilter returned %t, %v, expected a match when a is not x", matched, err)
+	}
+}
+
+func TestRenderFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		rendered string
+	}{
+		{`lookup("a")=="x"`, `lookup("a") == "x"`},
+		{`(lookup("a") == 1.50) && ((lookup("b") != null))`, `lookup("a") == 1.5 && lookup("b") != null`},
+		{`(lookup("a") == "x" || lookup("b") == "y") && lookup("c") == "z"`, `(lookup("a") == "x" || lookup("b") == "y") && lookup("c") == "z"`},
+		{`!lookup("a") == "x"`, `!(lookup("a") == "x")`},
+		{`!(lookup("a") == "x" && lookup("b") == "y")`, `!(lookup("a") == "x" && lookup("b") == "y")`},
+		{`string_contains(lookup("a"),"say \"hi\"\n")`, `string_contains(lookup("a"), "say \"hi\"\n")`},
+	}
+
+	for _, test := range tests {
+		expr, err := parseFilter(test.filter)
+		if err != nil {
+			t.Errorf("parseFilter(%q) returned unexpected error: %s", test.filter, err)
+			continue
+		}
+
+		rendered := renderFilter(expr)
+		if rendered != test.rendered {
+			t.Errorf("renderFilter(%q) = %q, expected %q", test.filter, rendered, test.rendered)
+		}
+
+		// Rendering must be stable, so a rendered filter renders back to itself.
+		reparsed, err := parseFilter(rendered)
+		if err != nil {
+			t.Errorf("parseFilter(%q) of rendered filter returned unexpected error: %s", rendered, err)
+			continue
+		}
+		if again := renderFilter(reparsed); again != rendered {
+			t.Errorf("renderFilter is not stable for %q, got %q", rendered, again)
+		}
+	}
+}
+
+func TestFilterSha256(t *testing.T) {
+	normalized := filterSha256(`lookup("a") == "x" && lookup("b") == 1`)
+
+	if hash := filterSha256("(lookup(\"a\")==\"x\")\n  && lookup(\"b\") == 1.0"); hash != normalized {
+		t.Errorf("filterSha256 of an equivalent filter returned %s, expected %s", hash, normalized)
+	}
+
+	if hash := filterSha256(`lookup("a") == "y" && lookup("b") == 1`); hash == normalized {
+		t.Errorf("filterSha256 of a different filter returned the same hash %s", hash)
+	}
+}
@@ -5,9 +5,9 @@ package provider
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	Ambar "github.com/ambarltd/ambar_go_client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -18,29 +18,48 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &FilterResource{}
 var _ resource.ResourceWithImportState = &FilterResource{}
+var _ resource.ResourceWithValidateConfig = &FilterResource{}
+var _ resource.ResourceWithModifyPlan = &FilterResource{}
 
 func NewFilterResource() resource.Resource {
-	return &FilterResource{}
+	return &FilterResource{filterContentsSensitive: true}
 }
 
 // FilterResource defines the resource implementation.
 type FilterResource struct {
-	client *Ambar.APIClient
+	client                  *Ambar.APIClient
+	ownershipTag            string
+	readOnly                bool
+	defaultDescription      descriptionDecoration
+	filterContentsSensitive bool
 }
 
 // FilterResourceModel describes the resource data model.
 type filterResourceModel struct {
-	DataSourceId   types.String `tfsdk:"data_source_id"`
-	Description    types.String `tfsdk:"description"`
-	FilterContents types.String `tfsdk:"filter_contents"`
-	State          types.String `tfsdk:"state"`
-	ResourceId     types.String `tfsdk:"resource_id"`
+	DataSourceId            types.String `tfsdk:"data_source_id"`
+	Description             types.String `tfsdk:"description"`
+	FilterContents          types.String `tfsdk:"filter_contents"`
+	FilterContentsSensitive types.Bool   `tfsdk:"filter_contents_sensitive"`
+	FilterContentsPlaintext types.String `tfsdk:"filter_contents_plaintext"`
+	FilterSha256            types.String `tfsdk:"filter_sha256"`
+	Expression              types.Object `tfsdk:"expression"`
+	TestCases               types.List   `tfsdk:"test_case"`
+	State                   types.String `tfsdk:"state"`
+	ResourceId              types.String `tfsdk:"resource_id"`
+}
+
+// filterTestCaseModel describes a sample record the Filter is evaluated against during plan.
+type filterTestCaseModel struct {
+	Name        types.String `tfsdk:"name"`
+	Record      types.Map    `tfsdk:"record"`
+	ExpectMatch types.Bool   `tfsdk:"expect_match"`
 }
 
 func (r *FilterResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -71,14 +90,30 @@ func (r *FilterResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 			"filter_contents": schema.StringAttribute{
-				MarkdownDescription: "A string filter statement using Ambar Filter syntax. See [Ambar documentation](https://docs.ambar.cloud) for more details on valid Ambar filtering operations on record sequences.",
-				Description:         "A string filter statement using Ambar Filter syntax. See [Ambar documentation](https://docs.ambar.cloud) for more details on valid Ambar filtering operations on record sequences.",
-				Required:            true,
+				MarkdownDescription: "A string filter statement using Ambar Filter syntax. See [Ambar documentation](https://docs.ambar.cloud) for more details on valid Ambar filtering operations on record sequences. Exactly one of `filter_contents` or `expression` must be set, when `expression` is used this holds the rendered filter.",
+				Description:         "A string filter statement using Ambar Filter syntax. See [Ambar documentation](https://docs.ambar.cloud) for more details on valid Ambar filtering operations on record sequences. Exactly one of filter_contents or expression must be set, when expression is used this holds the rendered filter.",
+				Optional:            true,
+				Computed:            true,
 				Sensitive:           true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"filter_contents_sensitive": schema.BoolAttribute{
+				MarkdownDescription: "Whether the Filter statement should be treated as sensitive. When `false`, the statement is copied to `filter_contents_plaintext` so plans show a readable diff of filter changes. Defaults to the provider `filter_contents_sensitive` setting, which defaults to `true`.",
+				Description:         "Whether the Filter statement should be treated as sensitive. When false, the statement is copied to filter_contents_plaintext so plans show a readable diff of filter changes. Defaults to the provider filter_contents_sensitive setting, which defaults to true.",
+				Optional:            true,
+			},
+			"filter_contents_plaintext": schema.StringAttribute{
+				MarkdownDescription: "A copy of `filter_contents` which is not marked as sensitive. Only set when `filter_contents_sensitive` is `false`.",
+				Description:         "A copy of filter_contents which is not marked as sensitive. Only set when filter_contents_sensitive is false.",
+				Computed:            true,
+			},
+			"filter_sha256": schema.StringAttribute{
+				MarkdownDescription: "The hex encoded SHA256 hash of the normalized Filter statement. Whitespace and redundant parentheses do not change the hash, so it can be used to tell whether a sensitive filter has really changed.",
+				Description:         "The hex encoded SHA256 hash of the normalized Filter statement. Whitespace and redundant parentheses do not change the hash, so it can be used to tell whether a sensitive filter has really changed.",
+				Computed:            true,
+			},
 			"state": schema.StringAttribute{
 				MarkdownDescription: "The current state of the Ambar resource.",
 				Description:         "The current state of the Ambar resource.",
@@ -93,6 +128,33 @@ func (r *FilterResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"expression": filterExpressionSchema(),
+			"test_case": schema.ListNestedBlock{
+				MarkdownDescription: "A sample record to evaluate the Filter against during plan. Planning fails if the Filter does not match, or unexpectedly matches, the record. Test cases are only evaluated locally and are not sent to Ambar.",
+				Description:         "A sample record to evaluate the Filter against during plan. Planning fails if the Filter does not match, or unexpectedly matches, the record. Test cases are only evaluated locally and are not sent to Ambar.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "A name for this test case, used when reporting failures.",
+							Description:         "A name for this test case, used when reporting failures.",
+							Optional:            true,
+						},
+						"record": schema.MapAttribute{
+							MarkdownDescription: "The sample record, as a map of DataSource column names to values.",
+							Description:         "The sample record, as a map of DataSource column names to values.",
+							Required:            true,
+							ElementType:         types.StringType,
+						},
+						"expect_match": schema.BoolAttribute{
+							MarkdownDescription: "Whether the Filter is expected to match the record.",
+							Description:         "Whether the Filter is expected to match the record.",
+							Required:            true,
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -102,21 +164,289 @@ func (r *FilterResource) Configure(ctx context.Context, req resource.ConfigureRe
 		return
 	}
 
-	client, ok := req.ProviderData.(*Ambar.APIClient)
+	providerData, ok := req.ProviderData.(*ambarProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *Ambar.APIClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ambarProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.ownershipTag = providerData.OwnershipTag
+	r.readOnly = providerData.ReadOnly
+	r.defaultDescription = providerData.DefaultDescription
+	r.filterContentsSensitive = providerData.FilterContentsSensitive
+}
+
+func (r *FilterResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data filterResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Expression.IsNull() {
+		if !data.FilterContents.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("expression"),
+				"Conflicting Filter configuration",
+				"Only one of filter_contents or expression may be set. Remove one of them from the configuration.",
+			)
+			return
+		}
+
+		_, _, diags := filterExpressionFromBlock(data.Expression, path.Root("expression"))
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	if data.FilterContents.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filter_contents"),
+			"Missing Filter configuration",
+			"One of filter_contents or expression must be set.",
+		)
+		return
+	}
+
+	// The filter may come from another resource, in which case we can only validate it during apply.
+	if data.FilterContents.IsUnknown() {
+		return
+	}
+
+	expr, err := parseFilter(data.FilterContents.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filter_contents"),
+			"Invalid Filter syntax",
+			"The filter_contents value is not a valid Ambar filter statement: "+err.Error(),
+		)
+		return
+	}
+
+	warnUnknownFilterFunctions(&resp.Diagnostics, path.Root("filter_contents"), expr)
+}
+
+// warnUnknownFilterFunctions adds a warning for each call in the filter to a function the provider does not know,
+// which can only be checked by Ambar during apply.
+func warnUnknownFilterFunctions(diags *diag.Diagnostics, attributePath path.Path, expr filterExpr) {
+	for _, call := range filterUnknownFunctions(expr) {
+		diags.AddAttributeWarning(
+			attributePath,
+			"Unknown Filter function",
+			fmt.Sprintf("The filter calls the function %q at %s, which this version of the provider does not know, so it can only be checked by Ambar during apply. "+
+				"Check the name if this is not a function added to Ambar recently.", call.Name, call.Pos),
+		)
+	}
+}
+
+func (r *FilterResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan filterResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Render the expression block so the plan shows, and Create sends, the resulting filter statement.
+	if !plan.Expression.IsNull() {
+		expr, known, diags := filterExpressionFromBlock(plan.Expression, path.Root("expression"))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		plan.FilterContents = types.StringUnknown()
+		if known {
+			plan.FilterContents = types.StringValue(renderFilter(expr))
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("filter_contents"), plan.FilterContents)...)
+	}
+
+	r.setFilterComputedValues(&plan)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("filter_contents_plaintext"), plan.FilterContentsPlaintext)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("filter_sha256"), plan.FilterSha256)...)
+
+	r.evaluateTestCases(ctx, plan, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The remaining checks need to call the Ambar API.
+	if r.client == nil {
+		return
+	}
+
+	// A DataSource created in the same apply will not have an id yet, in which case it can only be checked later.
+	if plan.DataSourceId.IsUnknown() {
+		return
+	}
+
+	// Filters which are not changing have already been checked, and their DataSource may be failing for reasons which
+	// should not block unrelated changes.
+	if !req.State.Raw.IsNull() && req.Plan.Raw.Equal(req.State.Raw) {
+		return
+	}
+
+	var describeDataSource Ambar.DescribeResourceRequest
+	describeDataSource.ResourceId = plan.DataSourceId.ValueString()
+
+	describeResourceResponse, httpResponse, err := r.client.AmbarAPI.DescribeDataSource(ctx).DescribeResourceRequest(describeDataSource).Execute()
+	var dataSourceState string
+	if describeResourceResponse != nil {
+		dataSourceState = describeResourceResponse.State
+	}
+
+	if !checkDependency(ctx, &resp.Diagnostics, path.Root("data_source_id"), "DataSource", plan.DataSourceId.ValueString(), dataSourceState, httpResponse, err) {
+		return
+	}
+
+	if plan.FilterContents.IsUnknown() || plan.FilterContents.IsNull() {
+		return
+	}
+
+	// Syntax errors are reported by ValidateConfig.
+	expr, err := parseFilter(plan.FilterContents.ValueString())
+	if err != nil {
+		return
+	}
+
+	columns, ok := dataSourceColumns(describeResourceResponse)
+	if !ok {
+		tflog.Debug(ctx, "DataSource does not list its columns, skipping Filter field check.")
+		return
+	}
+
+	for _, lookup := range filterLookups(expr) {
+		field, _ := filterLookupField(lookup)
+		if !columns[field] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("filter_contents"),
+				"Filter references an unknown column",
+				fmt.Sprintf("The filter looks up %q at %s, but DataSource %s does not capture that column, so the filter would never match. "+
+					"Captured columns are listed in the columns value of the DataSource data_source_config.",
+					field, lookup.Pos, plan.DataSourceId.ValueString()),
+			)
+		}
+	}
+}
+
+// setFilterComputedValues derives the filter_sha256 and filter_contents_plaintext values from filter_contents.
+func (r *FilterResource) setFilterComputedValues(data *filterResourceModel) {
+	if data.FilterContents.IsUnknown() {
+		data.FilterSha256 = types.StringUnknown()
+		data.FilterContentsPlaintext = types.StringUnknown()
+		return
+	}
+
+	if data.FilterContents.IsNull() {
+		data.FilterSha256 = types.StringNull()
+		data.FilterContentsPlaintext = types.StringNull()
+		return
+	}
+
+	data.FilterSha256 = types.StringValue(filterSha256(data.FilterContents.ValueString()))
+
+	sensitive := r.filterContentsSensitive
+	if !data.FilterContentsSensitive.IsNull() && !data.FilterContentsSensitive.IsUnknown() {
+		sensitive = data.FilterContentsSensitive.ValueBool()
+	}
+
+	data.FilterContentsPlaintext = types.StringNull()
+	if !sensitive {
+		data.FilterContentsPlaintext = data.FilterContents
+	}
+}
+
+// evaluateTestCases runs the Filter against the sample records of each test_case block, reporting any test case
+// where the result was not what was expected.
+func (r *FilterResource) evaluateTestCases(ctx context.Context, plan filterResourceModel, resp *resource.ModifyPlanResponse) {
+	if plan.TestCases.IsNull() || plan.TestCases.IsUnknown() || plan.FilterContents.IsUnknown() || plan.FilterContents.IsNull() {
+		return
+	}
+
+	expr, err := parseFilter(plan.FilterContents.ValueString())
+	if err != nil {
+		return
+	}
+
+	// Functions which are not known locally can't be evaluated, so the test cases are left unchecked.
+	if calls := filterUnknownFunctions(expr); len(calls) > 0 {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("test_case"),
+			"Filter test cases not evaluated",
+			fmt.Sprintf("The test cases were not evaluated, as the filter calls the function %q which this version of the provider can not evaluate.", calls[0].Name),
 		)
+		return
+	}
 
+	var testCases []filterTestCaseModel
+	resp.Diagnostics.Append(plan.TestCases.ElementsAs(ctx, &testCases, true)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	r.client = client
+	for i, testCase := range testCases {
+		if testCase.Record.IsUnknown() || testCase.ExpectMatch.IsUnknown() {
+			tflog.Debug(ctx, "Skipping Filter test case "+strconv.Itoa(i)+" with unknown values.")
+			continue
+		}
+
+		record := make(filterRecord)
+		resp.Diagnostics.Append(testCase.Record.ElementsAs(ctx, &record, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		name := "#" + strconv.Itoa(i+1)
+		if !testCase.Name.IsNull() && !testCase.Name.IsUnknown() {
+			name = strconv.Quote(testCase.Name.ValueString())
+		}
+
+		matched, err := evaluateFilter(expr, record)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("test_case").AtListIndex(i),
+				"Filter test case could not be evaluated",
+				fmt.Sprintf("Test case %s could not be evaluated: %s", name, err.Error()),
+			)
+			continue
+		}
+
+		if matched == testCase.ExpectMatch.ValueBool() {
+			continue
+		}
+
+		expected := "match the record, but it did not"
+		if !testCase.ExpectMatch.ValueBool() {
+			expected = "not match the record, but it did"
+		}
+
+		resp.Diagnostics.AddAttributeError(
+			path.Root("test_case").AtListIndex(i),
+			"Filter test case failed",
+			fmt.Sprintf("Test case %s expected the Filter to %s.\n\nFilter:\n  %s\n\nPredicates:\n  %s",
+				name, expected, renderFilter(expr), strings.Join(explainFilter(expr, record), "\n  ")),
+		)
+	}
 }
 
 func (r *FilterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "create", "Filter") {
+		return
+	}
+
 	// Retrieve values from plan
 	var plan filterResourceModel
 	diags := req.Plan.Get(ctx, &plan)
@@ -127,11 +457,11 @@ func (r *FilterResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	// Generate API request body from plan
 	var createFilter Ambar.CreateFilterRequest
-	createFilter.Description = plan.Description.ValueStringPointer()
+	decoration := r.defaultDescription.resolve("ambar_filter")
+	createFilter.Description = withOwnershipTag(decoration.apply(plan.Description.ValueStringPointer()), r.ownershipTag)
 
 	// Encode the customers filter string
-	encodedContents := base64.StdEncoding.EncodeToString([]byte(plan.FilterContents.ValueString()))
-	createFilter.FilterContents = encodedContents
+	createFilter.FilterContents = encodeFilterContents(plan.FilterContents.ValueString())
 
 	createFilter.DataSourceId = plan.DataSourceId.ValueString()
 
@@ -164,7 +494,9 @@ func (r *FilterResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	// Map response body to schema and populate Computed attribute values
 	plan.ResourceId = types.StringValue(createResourceResponse.ResourceId)
+	resp.Diagnostics.Append(decoration.save(ctx, resp.Private)...)
 	plan.State = types.StringValue(describeResourceResponse.State)
+	r.setFilterComputedValues(&plan)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -213,7 +545,10 @@ func (r *FilterResource) Read(ctx context.Context, req resource.ReadRequest, res
 	}
 
 	data.State = types.StringValue(describeResourceResponse.State)
-	data.Description = types.StringPointerValue(describeResourceResponse.Description)
+	decoration, diags := loadDescriptionDecoration(ctx, req.Private, r.defaultDescription.resolve("ambar_filter"))
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(decoration.save(ctx, resp.Private)...)
+	data.Description = types.StringPointerValue(decoration.strip(withoutOwnershipTag(describeResourceResponse.Description)))
 	data.DataSourceId = types.StringValue(describeResourceResponse.DataSourceId)
 
 	// Save updated data into Terraform state
@@ -221,6 +556,10 @@ func (r *FilterResource) Read(ctx context.Context, req resource.ReadRequest, res
 }
 
 func (r *FilterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "update", "Filter") {
+		return
+	}
+
 	// Ambar does not support resource updates, so there is nothing to do in this method. Instead, all attributes
 	// should include the PlanModifier indicating replacement is required on changes. RequiresReplace()
 
@@ -238,6 +577,10 @@ func (r *FilterResource) Update(ctx context.Context, req resource.UpdateRequest,
 }
 
 func (r *FilterResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "delete", "Filter") {
+		return
+	}
+
 	var data filterResourceModel
 
 	// Read Terraform prior state data into the model
@@ -297,3 +640,19 @@ func (r *FilterResource) Delete(ctx context.Context, req resource.DeleteRequest,
 func (r *FilterResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("resource_id"), req, resp)
 }
+
+// dataSourceColumns returns the set of columns a DataSource captures, as listed in its comma separated columns config.
+func dataSourceColumns(dataSource *Ambar.DataSource) (map[string]bool, bool) {
+	value, ok := dataSource.DataSourceConfig["columns"]
+	if !ok || value == nil {
+		return nil, false
+	}
+
+	columns := make(map[string]bool)
+	for _, column := range strings.Split(fmt.Sprint(value), ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns[column] = true
+		}
+	}
+	return columns, len(columns) > 0
+}
@@ -10,7 +10,47 @@ const (
 resource "ambar_filter" "test_filter" {
   data_source_id = ambar_data_source.test_data_source.resource_id
   description = "My test Filter"
-  filter_contents = ""
+  filter_contents = "lookup(\"columns\") == \"value\""
+}`
+
+	exampleFilterExpressionResourceConfig = `
+resource "ambar_filter" "test_filter_expression" {
+  data_source_id = ambar_data_source.test_data_source.resource_id
+  description = "My test Filter expression"
+  filter_contents_sensitive = false
+  expression {
+    and {
+      comparison {
+        field = "columns"
+        operator = "=="
+        value = "value"
+      }
+      not {
+        comparison {
+          field = "serial_column"
+          operator = "<"
+          value = "10"
+          value_type = "number"
+        }
+      }
+    }
+  }
+  test_case {
+    name = "matches value"
+    record = {
+      columns = "value"
+      serial_column = "12"
+    }
+    expect_match = true
+  }
+  test_case {
+    name = "skips early serials"
+    record = {
+      columns = "value"
+      serial_column = "3"
+    }
+    expect_match = false
+  }
 }`
 )
 
@@ -25,6 +65,29 @@ func TestAmbarFilterResource(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					// Verify placeholder id attribute
 					resource.TestCheckResourceAttrSet("ambar_filter.test_filter", "resource_id"),
+					resource.TestCheckResourceAttrSet("ambar_filter.test_filter", "filter_sha256"),
+					// Filters are sensitive by default, so no plaintext copy should be kept
+					resource.TestCheckNoResourceAttr("ambar_filter.test_filter", "filter_contents_plaintext"),
+				),
+			},
+		},
+	})
+}
+
+func TestAmbarFilterResourceExpression(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: providerConfig + exampleDataSourceConfig + exampleFilterExpressionResourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ambar_filter.test_filter_expression", "resource_id"),
+					// Verify the expression block was rendered to filter syntax
+					resource.TestCheckResourceAttr("ambar_filter.test_filter_expression", "filter_contents",
+						`lookup("columns") == "value" && !(lookup("serial_column") < 10)`),
+					resource.TestCheckResourceAttr("ambar_filter.test_filter_expression", "filter_contents_plaintext",
+						`lookup("columns") == "value" && !(lookup("serial_column") < 10)`),
 				),
 			},
 		},
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The provider talks to Ambar through a configurable HTTP client, so it can be used through a corporate egress proxy,
+// a TLS-inspecting gateway with its own CA, or against a local mock of the Ambar API.
+
+// httpClientConfig holds the transport settings of the provider.
+type httpClientConfig struct {
+	// ProxyURL is the proxy all requests are sent through. The HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment
+	// variables are used when nil.
+	ProxyURL *url.URL
+	// CACertsPEM are trusted in addition to the system certificate pool.
+	CACertsPEM [][]byte
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+	// RequestTimeout bounds each request, including reading the response body. Requests do not time out when zero.
+	RequestTimeout time.Duration
+}
+
+// parseEndpoint returns the URL of the Ambar API from an endpoint, which is either a bare host such as
+// "euw1.api.ambar.cloud", using HTTPS, or a full URL with a scheme and optional base path.
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpointURL.Scheme != "http" && endpointURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q, expected http or https", endpointURL.Scheme)
+	}
+	if endpointURL.Host == "" {
+		return nil, errors.New("no host was given")
+	}
+	if endpointURL.RawQuery != "" || endpointURL.Fragment != "" || endpointURL.User != nil {
+		return nil, errors.New("the endpoint may only have a scheme, host and base path")
+	}
+
+	endpointURL.Path = strings.TrimSuffix(endpointURL.Path, "/")
+	endpointURL.RawPath = ""
+	return endpointURL, nil
+}
+
+// parseProxyURL returns the URL of a proxy, which must use the http, https or socks5 scheme.
+func parseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, expected http, https or socks5", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, errors.New("no host was given")
+	}
+	return proxyURL, nil
+}
+
+// checkCACertsPEM returns an error when pem holds no certificates which can be trusted.
+func checkCACertsPEM(pem []byte) error {
+	if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+		return errors.New("no PEM encoded certificates were found")
+	}
+	return nil
+}
+
+// newHTTPClient returns an HTTP client using the transport settings. CA certificates must have been checked with
+// checkCACertsPEM.
+func newHTTPClient(config httpClientConfig) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.ProxyURL != nil {
+		transport.Proxy = http.ProxyURL(config.ProxyURL)
+	}
+
+	if len(config.CACertsPEM) > 0 || config.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// #nosec G402 -- only when the practitioner sets insecure_skip_verify, which warns during every run.
+			InsecureSkipVerify: config.InsecureSkipVerify,
+		}
+	}
+
+	if len(config.CACertsPEM) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		for _, pem := range config.CACertsPEM {
+			rootCAs.AppendCertsFromPEM(pem)
+		}
+		transport.TLSClientConfig.RootCAs = rootCAs
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   config.RequestTimeout,
+	}
+}
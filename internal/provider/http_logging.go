@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// loggingTransport logs the Ambar API requests made by the provider. Every request is logged with its method, path,
+// status, latency and request id at DEBUG, and request and response bodies are added at TRACE. Credentials are
+// redacted, so logs can be shared when reporting issues.
+type loggingTransport struct {
+	next http.RoundTripper
+	// traceBodies buffers request and response bodies so they can be logged, which is only done when TRACE logs are
+	// written, so large responses are otherwise streamed to the client.
+	traceBodies bool
+}
+
+// newLoggingTransport returns a transport logging the requests sent through next, with their bodies when
+// traceBodies is set.
+func newLoggingTransport(next http.RoundTripper, traceBodies bool) http.RoundTripper {
+	return &loggingTransport{next: next, traceBodies: traceBodies}
+}
+
+// providerLogLevelEnvVars set the level of the provider logs, in order of precedence.
+var providerLogLevelEnvVars = []string{"TF_LOG_PROVIDER_AMBAR", "TF_LOG_PROVIDER", "TF_LOG"}
+
+// traceLoggingEnabled reports whether the provider logs are written at TRACE. tflog does not expose its level, so it
+// is read from the same environment variables.
+func traceLoggingEnabled() bool {
+	for _, envVar := range providerLogLevelEnvVars {
+		if level := os.Getenv(envVar); level != "" {
+			return strings.EqualFold(level, "TRACE") || strings.EqualFold(level, "JSON")
+		}
+	}
+	return false
+}
+
+// redactedFields are the request and response fields holding credentials or filters, which may embed secrets.
+var redactedFields = []string{"x-api-key", "password", "username", "filterContents"}
+
+// requestIdHeaders are the response headers which may carry the id Ambar gave the request.
+var requestIdHeaders = []string{"X-Amzn-Requestid", "X-Amz-Request-Id", "X-Request-Id", "Apigw-Requestid"}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	// Mask the key wherever it may appear, such as in error messages.
+	if apiKey := req.Header.Get("x-api-key"); apiKey != "" {
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, apiKey)
+		ctx = tflog.MaskMessageStrings(ctx, apiKey)
+	}
+
+	fields := map[string]any{
+		"http_method": req.Method,
+		"http_path":   req.URL.Path,
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields["http_duration_ms"] = time.Since(start).Milliseconds()
+
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.Debug(ctx, "Ambar API request failed", fields)
+		return resp, err
+	}
+
+	fields["http_status"] = resp.StatusCode
+	for _, header := range requestIdHeaders {
+		if requestId := resp.Header.Get(header); requestId != "" {
+			fields["http_request_id"] = requestId
+			break
+		}
+	}
+	tflog.Debug(ctx, "Ambar API request", fields)
+
+	if !t.traceBodies {
+		return resp, nil
+	}
+
+	// The request body is read again through GetBody, so the request sent is left untouched.
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			requestBody, _ := io.ReadAll(body)
+			body.Close()
+			ctx = maskBodySecrets(ctx, requestBody)
+			fields["http_request_body"] = string(requestBody)
+		}
+	}
+
+	if resp.Body != nil {
+		responseBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		// Hand the body back to the client, along with any error reading it.
+		var rest io.Reader = bytes.NewReader(responseBody)
+		if err != nil {
+			rest = io.MultiReader(rest, &errorReader{err: err})
+		}
+		resp.Body = io.NopCloser(rest)
+		ctx = maskBodySecrets(ctx, responseBody)
+		fields["http_response_body"] = string(responseBody)
+	}
+
+	tflog.Trace(ctx, "Ambar API request bodies", fields)
+
+	return resp, nil
+}
+
+// maskBodySecrets masks the values of the redacted fields of a JSON body wherever they are logged with ctx.
+func maskBodySecrets(ctx context.Context, body []byte) context.Context {
+	secrets := bodySecrets(json.RawMessage(body), nil)
+	if len(secrets) == 0 {
+		return ctx
+	}
+
+	ctx = tflog.MaskAllFieldValuesStrings(ctx, secrets...)
+	return tflog.MaskMessageStrings(ctx, secrets...)
+}
+
+// bodySecrets appends the values of the redacted fields within a JSON value to secrets, in every form they may take
+// in the body: the encoded value of any type, and the text of string values. Objects and lists are searched at any
+// depth, as are strings holding encoded JSON.
+func bodySecrets(value json.RawMessage, secrets []string) []string {
+	var object map[string]json.RawMessage
+	var list []json.RawMessage
+	var text string
+
+	switch {
+	case json.Unmarshal(value, &object) == nil:
+		for name, field := range object {
+			if !slices.ContainsFunc(redactedFields, func(redactedField string) bool { return strings.EqualFold(name, redactedField) }) {
+				secrets = bodySecrets(field, secrets)
+				continue
+			}
+
+			if encoded := string(bytes.TrimSpace(field)); encoded != "null" {
+				secrets = append(secrets, encoded)
+			}
+			if json.Unmarshal(field, &text) == nil && text != "" {
+				secrets = append(secrets, text)
+			}
+		}
+	case json.Unmarshal(value, &list) == nil:
+		for _, element := range list {
+			secrets = bodySecrets(element, secrets)
+		}
+	case json.Unmarshal(value, &text) == nil:
+		// Secrets within encoded JSON are escaped in the body which holds it.
+		if nested := bodySecrets(json.RawMessage(text), nil); len(nested) > 0 {
+			for _, secret := range nested {
+				escaped, _ := json.Marshal(secret)
+				secrets = append(secrets, secret, string(escaped[1:len(escaped)-1]))
+			}
+		}
+	}
+
+	return secrets
+}
+
+// errorReader returns err once the body read before it is exhausted.
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLoggingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amzn-Requestid", "request-1234")
+		_, _ = w.Write([]byte(`{"dataSourceConfig": {"username": "admin", "password": 12345678, "hostname": "db.example.com"}}`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/filter", strings.NewReader(`{"filterContents": "lookup(\"secret\") == \"1\"", "description": "kept"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("x-api-key", "top-secret-key")
+
+	client := &http.Client{Transport: newLoggingTransport(http.DefaultTransport, true)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request returned error: %s", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !strings.Contains(string(body), "12345678") {
+		t.Errorf("the response body was not passed on unchanged: %s", body)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected a DEBUG and a TRACE entry, got %d", len(entries))
+	}
+
+	debug := entries[0]
+	if debug["@level"] != "debug" || debug["http_method"] != "POST" || debug["http_path"] != "/filter" || debug["http_status"] != float64(200) || debug["http_request_id"] != "request-1234" {
+		t.Errorf("unexpected DEBUG entry: %v", debug)
+	}
+	if _, ok := debug["http_duration_ms"]; !ok {
+		t.Errorf("DEBUG entry has no latency: %v", debug)
+	}
+
+	logged := fmt.Sprint(entries)
+	for _, secret := range []string{"top-secret-key", "admin", "12345678", "secret"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("logs contain %q", secret)
+		}
+	}
+
+	trace := entries[1]
+	if trace["@level"] != "trace" || !strings.Contains(trace["http_request_body"].(string), `"kept"`) || !strings.Contains(trace["http_response_body"].(string), "db.example.com") {
+		t.Errorf("unexpected TRACE entry: %v", trace)
+	}
+}
+
+func TestLoggingTransportWithoutTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"resourceId": "source-1"}`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/source", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: newLoggingTransport(http.DefaultTransport, false)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request returned error: %s", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != `{"resourceId": "source-1"}` {
+		t.Errorf("the response body was not passed on unchanged: %s", body)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0]["@level"] != "debug" {
+		t.Errorf("expected only a DEBUG entry, got: %v", entries)
+	}
+}
+
+func TestBodySecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"string", `{"Password": "a\"b", "other": "value"}`, []string{`"a\"b"`, `a"b`}},
+		{"non-string", `{"password": 1234, "username": {"first": "admin"}, "x-api-key": null}`, []string{`1234`, `{"first": "admin"}`}},
+		{"nested", `{"dataSourceConfig": {"username": "admin"}, "filters": [{"filterContents": "f"}]}`, []string{`"admin"`, `admin`, `"f"`, `f`}},
+		{"encoded", `{"config": "{\"password\": \"hunter2\"}"}`, []string{`"hunter2"`, `\"hunter2\"`, `hunter2`, `hunter2`}},
+		{"not JSON", `password=hunter2`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			secrets := bodySecrets(json.RawMessage(test.body), nil)
+			slices.Sort(secrets)
+			slices.Sort(test.expected)
+
+			if !slices.Equal(secrets, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, secrets)
+			}
+		})
+	}
+}
+
+func TestTraceLoggingEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected bool
+	}{
+		{"unset", map[string]string{}, false},
+		{"TF_LOG trace", map[string]string{"TF_LOG": "trace"}, true},
+		{"TF_LOG json", map[string]string{"TF_LOG": "JSON"}, true},
+		{"TF_LOG debug", map[string]string{"TF_LOG": "DEBUG"}, false},
+		{"provider level takes precedence", map[string]string{"TF_LOG": "TRACE", "TF_LOG_PROVIDER": "INFO"}, false},
+		{"ambar level takes precedence", map[string]string{"TF_LOG_PROVIDER": "INFO", "TF_LOG_PROVIDER_AMBAR": "TRACE"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, envVar := range providerLogLevelEnvVars {
+				t.Setenv(envVar, test.env[envVar])
+			}
+
+			if enabled := traceLoggingEnabled(); enabled != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, enabled)
+			}
+		})
+	}
+}
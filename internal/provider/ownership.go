@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Ambar resources only have a free text description, so the provider records which Terraform configuration owns a
+// resource by appending a marker to its description, such as "My DataSource [terraform-owner:payments-prod]". The
+// marker is removed again when reading the resource, so it never shows up in state or plans.
+
+// ownershipTagPattern matches the tags which can be safely embedded in, and parsed back out of, a description.
+var ownershipTagPattern = regexp.MustCompile(`^[A-Za-z0-9_.:/@-]{1,128}$`)
+
+// ownershipMarkerPattern matches a marker at the end of a description, capturing the tag.
+var ownershipMarkerPattern = regexp.MustCompile(`(?:^| )\[terraform-owner:([A-Za-z0-9_.:/@-]{1,128})\]$`)
+
+// ownershipMarker returns the marker appended to descriptions for the tag.
+func ownershipMarker(tag string) string {
+	return "[terraform-owner:" + tag + "]"
+}
+
+// withOwnershipTag appends the marker for the tag to a description being written to Ambar. Descriptions are returned
+// unchanged when no tag is configured.
+func withOwnershipTag(description *string, tag string) *string {
+	if tag == "" {
+		return description
+	}
+
+	tagged := ownershipMarker(tag)
+	if description != nil {
+		tagged = *description + " " + tagged
+	}
+	return &tagged
+}
+
+// withoutOwnershipTag removes a marker from a description read from Ambar, returning the description as configured.
+// Markers of any tag are removed, so changing the provider's ownership_tag does not cause a diff on existing resources.
+func withoutOwnershipTag(description *string) *string {
+	if description == nil {
+		return nil
+	}
+
+	location := ownershipMarkerPattern.FindStringIndex(*description)
+	if location == nil {
+		return description
+	}
+
+	// A description made up of only the marker was written for a resource without a description.
+	if location[0] == 0 && !strings.HasPrefix(*description, " ") {
+		return nil
+	}
+
+	stripped := (*description)[:location[0]]
+	return &stripped
+}
+
+// ownershipTagOf returns the tag of the marker in a description, if any.
+func ownershipTagOf(description string) (string, bool) {
+	match := ownershipMarkerPattern.FindStringSubmatch(description)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestOwnershipTag(t *testing.T) {
+	description := "My DataSource"
+	empty := ""
+
+	tests := []struct {
+		name        string
+		description *string
+		tagged      string
+	}{
+		{"description", &description, "My DataSource [terraform-owner:payments-prod]"},
+		{"no description", nil, "[terraform-owner:payments-prod]"},
+		{"empty description", &empty, " [terraform-owner:payments-prod]"},
+	}
+
+	for _, test := range tests {
+		tagged := withOwnershipTag(test.description, "payments-prod")
+		if tagged == nil || *tagged != test.tagged {
+			t.Errorf("%s: withOwnershipTag returned %v, expected %q", test.name, tagged, test.tagged)
+			continue
+		}
+
+		if tag, ok := ownershipTagOf(*tagged); !ok || tag != "payments-prod" {
+			t.Errorf("%s: ownershipTagOf(%q) returned %q, %t", test.name, *tagged, tag, ok)
+		}
+
+		stripped := withoutOwnershipTag(tagged)
+		if (stripped == nil) != (test.description == nil) || (stripped != nil && *stripped != *test.description) {
+			t.Errorf("%s: withoutOwnershipTag(%q) returned %v, expected %v", test.name, *tagged, stripped, test.description)
+		}
+	}
+
+	if untagged := withOwnershipTag(&description, ""); untagged != &description {
+		t.Errorf("withOwnershipTag expected the description to be unchanged without a tag")
+	}
+
+	if _, ok := ownershipTagOf("Mentions [terraform-owner:x] in the middle"); ok {
+		t.Errorf("ownershipTagOf expected a marker which is not at the end of the description to be ignored")
+	}
+}
@@ -0,0 +1,830 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	Ambar "github.com/ambarltd/ambar_go_client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &pipelineResource{}
+var _ resource.ResourceWithConfigure = &pipelineResource{}
+var _ resource.ResourceWithValidateConfig = &pipelineResource{}
+var _ resource.ResourceWithModifyPlan = &pipelineResource{}
+
+// pipelinePollInterval is how often pipeline members are described while waiting for them to be created or deleted.
+var pipelinePollInterval = 10 * time.Second
+
+// pipelineRollbackTimeout bounds how long a rollback waits for the members created so far to be deleted.
+const pipelineRollbackTimeout = 15 * time.Minute
+
+func NewPipelineResource() resource.Resource {
+	return &pipelineResource{}
+}
+
+// pipelineResource defines the resource implementation. A pipeline manages a DataSource, the Filters applied to it and
+// a DataDestination receiving the filtered records as a single resource, so a failure part way through creating them
+// does not leave the other members behind.
+type pipelineResource struct {
+	client       *Ambar.APIClient
+	ownershipTag string
+	readOnly     bool
+}
+
+// pipelineResourceModel describes the resource data model.
+type pipelineResourceModel struct {
+	Source      types.Object `tfsdk:"source"`
+	Filters     types.List   `tfsdk:"filter"`
+	Destination types.Object `tfsdk:"destination"`
+}
+
+// pipelineSourceModel describes the DataSource of the pipeline.
+type pipelineSourceModel struct {
+	DataSourceType   types.String `tfsdk:"data_source_type"`
+	Description      types.String `tfsdk:"description"`
+	DataSourceConfig types.Map    `tfsdk:"data_source_config"`
+	State            types.String `tfsdk:"state"`
+	ResourceId       types.String `tfsdk:"resource_id"`
+}
+
+// pipelineFilterModel describes a Filter applied to the DataSource of the pipeline.
+type pipelineFilterModel struct {
+	Description    types.String `tfsdk:"description"`
+	FilterContents types.String `tfsdk:"filter_contents"`
+	State          types.String `tfsdk:"state"`
+	ResourceId     types.String `tfsdk:"resource_id"`
+}
+
+// pipelineDestinationModel describes the DataDestination of the pipeline.
+type pipelineDestinationModel struct {
+	Description         types.String `tfsdk:"description"`
+	DestinationEndpoint types.String `tfsdk:"destination_endpoint"`
+	Username            types.String `tfsdk:"username"`
+	Password            types.String `tfsdk:"password"`
+	State               types.String `tfsdk:"state"`
+	ResourceId          types.String `tfsdk:"resource_id"`
+}
+
+var pipelineSourceAttrTypes = map[string]attr.Type{
+	"data_source_type":   types.StringType,
+	"description":        types.StringType,
+	"data_source_config": types.MapType{ElemType: types.StringType},
+	"state":              types.StringType,
+	"resource_id":        types.StringType,
+}
+
+var pipelineFilterAttrTypes = map[string]attr.Type{
+	"description":     types.StringType,
+	"filter_contents": types.StringType,
+	"state":           types.StringType,
+	"resource_id":     types.StringType,
+}
+
+var pipelineDestinationAttrTypes = map[string]attr.Type{
+	"description":          types.StringType,
+	"destination_endpoint": types.StringType,
+	"username":             types.StringType,
+	"password":             types.StringType,
+	"state":                types.StringType,
+	"resource_id":          types.StringType,
+}
+
+// pipelineMember identifies an Ambar resource created for a pipeline.
+type pipelineMember struct {
+	ResourceType string
+	ResourceId   string
+}
+
+func (r *pipelineResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pipeline"
+}
+
+func (r *pipelineResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Ambar Pipeline resource. Creates a DataSource, the Filters applied to it and a DataDestination receiving the filtered record sequences as a single resource. Members are created in dependency order and each waits to be `READY` before the next is created. If any member fails to create, the members already created are deleted again. Any change to a member replaces the whole pipeline.",
+		Description:         "Ambar Pipeline resource. Creates a DataSource, the Filters applied to it and a DataDestination receiving the filtered record sequences as a single resource. Members are created in dependency order and each waits to be READY before the next is created. If any member fails to create, the members already created are deleted again. Any change to a member replaces the whole pipeline.",
+
+		Blocks: map[string]schema.Block{
+			"source": schema.SingleNestedBlock{
+				MarkdownDescription: "The DataSource records are imported from. This block is required, and accepts the same arguments as `ambar_data_source`.",
+				Description:         "The DataSource records are imported from. This block is required, and accepts the same arguments as ambar_data_source.",
+				Attributes: map[string]schema.Attribute{
+					"data_source_type": schema.StringAttribute{
+						MarkdownDescription: "The type of durable storage being connected to, such as postgres. See Ambar documentation for a full list of supported data_source_types.",
+						Description:         "The type of durable storage being connected to, such as postgres. See Ambar documentation for a full list of supported data_source_types.",
+						Required:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
+					},
+					"description": schema.StringAttribute{
+						MarkdownDescription: "A user friendly description of the DataSource.",
+						Description:         "A user friendly description of the DataSource.",
+						Optional:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
+					},
+					"data_source_config": schema.MapAttribute{
+						MarkdownDescription: "A Key Value map of further DataSource configurations specific to the type of database the DataSource will connect to. See Ambar documentation for a list of required parameters.",
+						Description:         "A Key Value map of further DataSource configurations specific to the type of database the DataSource will connect to. See Ambar documentation for a list of required parameters.",
+						Required:            true,
+						Sensitive:           true,
+						ElementType:         types.StringType,
+						PlanModifiers: []planmodifier.Map{
+							mapplanmodifier.RequiresReplace(),
+						},
+					},
+					"state": schema.StringAttribute{
+						MarkdownDescription: "The current state of the DataSource.",
+						Description:         "The current state of the DataSource.",
+						Computed:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
+					},
+					"resource_id": schema.StringAttribute{
+						MarkdownDescription: "The unique Ambar resource id of the DataSource.",
+						Description:         "The unique Ambar resource id of the DataSource.",
+						Computed:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
+					},
+				},
+			},
+			"filter": schema.ListNestedBlock{
+				MarkdownDescription: "A Filter applied to the DataSource, selecting the records delivered to the DataDestination. At least one is required, and each accepts the same `description` and `filter_contents` arguments as `ambar_filter`.",
+				Description:         "A Filter applied to the DataSource, selecting the records delivered to the DataDestination. At least one is required, and each accepts the same description and filter_contents arguments as ambar_filter.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplaceIf(
+						func(ctx context.Context, req planmodifier.ListRequest, resp *listplanmodifier.RequiresReplaceIfFuncResponse) {
+							// Changes within a Filter are handled by its attributes, so only adding or removing Filters is checked here.
+							resp.RequiresReplace = len(req.StateValue.Elements()) != len(req.PlanValue.Elements())
+						},
+						"Adding or removing Filters requires replacement of the pipeline.",
+						"Adding or removing Filters requires replacement of the pipeline."),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"description": schema.StringAttribute{
+							MarkdownDescription: "A user friendly description of the Filter.",
+							Description:         "A user friendly description of the Filter.",
+							Optional:            true,
+							PlanModifiers: []planmodifier.String{
+								stringplanmodifier.RequiresReplace(),
+							},
+						},
+						"filter_contents": schema.StringAttribute{
+							MarkdownDescription: "The filter statement using Ambar Filter syntax, see `ambar_filter` for details.",
+							Description:         "The filter statement using Ambar Filter syntax, see ambar_filter for details.",
+							Required:            true,
+							Sensitive:           true,
+							PlanModifiers: []planmodifier.String{
+								stringplanmodifier.RequiresReplace(),
+							},
+						},
+						"state": schema.StringAttribute{
+							MarkdownDescription: "The current state of the Filter.",
+							Description:         "The current state of the Filter.",
+							Computed:            true,
+							PlanModifiers: []planmodifier.String{
+								stringplanmodifier.UseStateForUnknown(),
+							},
+						},
+						"resource_id": schema.StringAttribute{
+							MarkdownDescription: "The unique Ambar resource id of the Filter.",
+							Description:         "The unique Ambar resource id of the Filter.",
+							Computed:            true,
+							PlanModifiers: []planmodifier.String{
+								stringplanmodifier.UseStateForUnknown(),
+							},
+						},
+					},
+				},
+			},
+			"destination": schema.SingleNestedBlock{
+				MarkdownDescription: "The DataDestination receiving records matching any of the Filters. This block is required, and accepts the same arguments as `ambar_data_destination`, except `filter_ids` which are taken from the `filter` blocks.",
+				Description:         "The DataDestination receiving records matching any of the Filters. This block is required, and accepts the same arguments as ambar_data_destination, except filter_ids which are taken from the filter blocks.",
+				Attributes: map[string]schema.Attribute{
+					"description": schema.StringAttribute{
+						MarkdownDescription: "A user friendly description of the DataDestination.",
+						Description:         "A user friendly description of the DataDestination.",
+						Optional:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
+					},
+					"destination_endpoint": schema.StringAttribute{
+						MarkdownDescription: "The HTTP endpoint where Ambar will send your filtered record sequences to.",
+						Description:         "The HTTP endpoint where Ambar will send your filtered record sequences to.",
+						Required:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
+					},
+					"username": schema.StringAttribute{
+						MarkdownDescription: "A username credential which Ambar can use to communicate with your destination.",
+						Description:         "A username credential which Ambar can use to communicate with your destination.",
+						Required:            true,
+						Sensitive:           true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
+					},
+					"password": schema.StringAttribute{
+						MarkdownDescription: "A password credential which Ambar can use to communicate with your destination.",
+						Description:         "A password credential which Ambar can use to communicate with your destination.",
+						Required:            true,
+						Sensitive:           true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
+					},
+					"state": schema.StringAttribute{
+						MarkdownDescription: "The current state of the DataDestination.",
+						Description:         "The current state of the DataDestination.",
+						Computed:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
+					},
+					"resource_id": schema.StringAttribute{
+						MarkdownDescription: "The unique Ambar resource id of the DataDestination.",
+						Description:         "The unique Ambar resource id of the DataDestination.",
+						Computed:            true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *pipelineResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ambarProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ambarProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.ownershipTag = providerData.OwnershipTag
+	r.readOnly = providerData.ReadOnly
+}
+
+func (r *pipelineResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data pipelineResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Source.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("source"),
+			"Missing source block",
+			"A pipeline requires a source block describing its DataSource.",
+		)
+	}
+
+	if data.Destination.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("destination"),
+			"Missing destination block",
+			"A pipeline requires a destination block describing its DataDestination.",
+		)
+	}
+
+	if data.Filters.IsUnknown() {
+		return
+	}
+
+	if len(data.Filters.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filter"),
+			"Missing filter block",
+			"A pipeline requires at least one filter block selecting the records delivered to its DataDestination.",
+		)
+		return
+	}
+
+	var filters []pipelineFilterModel
+	resp.Diagnostics.Append(data.Filters.ElementsAs(ctx, &filters, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i, filter := range filters {
+		if filter.FilterContents.IsUnknown() || filter.FilterContents.IsNull() {
+			continue
+		}
+
+		expr, err := parseFilter(filter.FilterContents.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("filter").AtListIndex(i).AtName("filter_contents"),
+				"Invalid Filter syntax",
+				"The filter_contents could not be parsed: "+filterErrorMessage(err),
+			)
+			continue
+		}
+
+		warnUnknownFilterFunctions(&resp.Diagnostics, path.Root("filter").AtListIndex(i).AtName("filter_contents"), expr)
+	}
+}
+
+func (r *pipelineResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Only existing pipelines can have members which are no longer usable.
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var filters types.List
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("filter"), &filters)...)
+
+	var plannedFilters types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("filter"), &plannedFilters)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members := []path.Path{path.Root("source"), path.Root("destination")}
+	for i := range filters.Elements() {
+		if i < len(plannedFilters.Elements()) {
+			members = append(members, path.Root("filter").AtListIndex(i))
+		}
+	}
+
+	// A member which failed, or was deleted outside of Terraform, can only be fixed by recreating the pipeline, as the
+	// other members refer to it by resource id.
+	for _, member := range members {
+		var state types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, member.AtName("state"), &state)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		switch state.ValueString() {
+		case "FAILED", "DELETING", "DELETED":
+			tflog.Info(ctx, "Pipeline member is in the "+state.ValueString()+" state, which requires replace")
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, member.AtName("state"), types.StringUnknown())...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, member.AtName("resource_id"), types.StringUnknown())...)
+			resp.RequiresReplace = append(resp.RequiresReplace, member.AtName("state"))
+		}
+	}
+}
+
+func (r *pipelineResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "create", "pipeline") {
+		return
+	}
+
+	// Retrieve values from plan
+	var plan pipelineResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var source pipelineSourceModel
+	var filters []pipelineFilterModel
+	var destination pipelineDestinationModel
+	resp.Diagnostics.Append(plan.Source.As(ctx, &source, basetypes.ObjectAsOptions{})...)
+	resp.Diagnostics.Append(plan.Filters.ElementsAs(ctx, &filters, false)...)
+	resp.Diagnostics.Append(plan.Destination.As(ctx, &destination, basetypes.ObjectAsOptions{})...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Members created so far, in creation order, so they can be deleted again if a later step fails.
+	var created []pipelineMember
+
+	// Create the DataSource
+	var createDataSource Ambar.CreateDataSourceRequest
+	createDataSource.DataSourceType = source.DataSourceType.ValueString()
+	createDataSource.Description = withOwnershipTag(source.Description.ValueStringPointer(), r.ownershipTag)
+	createDataSource.DataSourceConfig = make(map[string]string)
+	resp.Diagnostics.Append(source.DataSourceConfig.ElementsAs(ctx, &createDataSource.DataSourceConfig, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createSourceResponse, httpResponse, err := r.client.AmbarAPI.CreateDataSource(ctx).CreateDataSourceRequest(createDataSource).Execute()
+	if err != nil || createSourceResponse == nil {
+		resp.Diagnostics.AddError("Error creating pipeline DataSource", "Could not create DataSource: "+pipelineApiError(httpResponse, err))
+		return
+	}
+
+	sourceMember := pipelineMember{ResourceType: "DataSource", ResourceId: createSourceResponse.ResourceId}
+	created = append(created, sourceMember)
+	tflog.Info(ctx, "Created pipeline DataSource "+sourceMember.ResourceId+", waiting for it to be READY")
+
+	if source.State, err = r.waitForMemberReady(ctx, sourceMember); err != nil {
+		resp.Diagnostics.AddError("Error creating pipeline DataSource", err.Error())
+		r.rollback(ctx, resp, created)
+		return
+	}
+	source.ResourceId = types.StringValue(sourceMember.ResourceId)
+
+	// Create each Filter against the new DataSource
+	filterIds := make([]string, 0, len(filters))
+	for i := range filters {
+		var createFilter Ambar.CreateFilterRequest
+		createFilter.Description = withOwnershipTag(filters[i].Description.ValueStringPointer(), r.ownershipTag)
+		createFilter.FilterContents = encodeFilterContents(filters[i].FilterContents.ValueString())
+		createFilter.DataSourceId = sourceMember.ResourceId
+
+		createFilterResponse, httpResponse, err := r.client.AmbarAPI.CreateFilter(ctx).CreateFilterRequest(createFilter).Execute()
+		if err != nil || createFilterResponse == nil {
+			resp.Diagnostics.AddAttributeError(path.Root("filter").AtListIndex(i), "Error creating pipeline Filter", "Could not create Filter: "+pipelineApiError(httpResponse, err))
+			r.rollback(ctx, resp, created)
+			return
+		}
+
+		filterMember := pipelineMember{ResourceType: "Filter", ResourceId: createFilterResponse.ResourceId}
+		created = append(created, filterMember)
+		tflog.Info(ctx, "Created pipeline Filter "+filterMember.ResourceId+", waiting for it to be READY")
+
+		if filters[i].State, err = r.waitForMemberReady(ctx, filterMember); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("filter").AtListIndex(i), "Error creating pipeline Filter", err.Error())
+			r.rollback(ctx, resp, created)
+			return
+		}
+		filters[i].ResourceId = types.StringValue(filterMember.ResourceId)
+		filterIds = append(filterIds, filterMember.ResourceId)
+	}
+
+	// Create the DataDestination using all of the Filters
+	var createDataDestination Ambar.CreateDataDestinationRequest
+	createDataDestination.FilterIds = filterIds
+	createDataDestination.Description = withOwnershipTag(destination.Description.ValueStringPointer(), r.ownershipTag)
+	createDataDestination.Username = destination.Username.ValueString()
+	createDataDestination.Password = destination.Password.ValueString()
+	createDataDestination.DestinationEndpoint = destination.DestinationEndpoint.ValueString()
+
+	createDestinationResponse, httpResponse, err := r.client.AmbarAPI.CreateDataDestination(ctx).CreateDataDestinationRequest(createDataDestination).Execute()
+	if err != nil || createDestinationResponse == nil {
+		resp.Diagnostics.AddError("Error creating pipeline DataDestination", "Could not create DataDestination: "+pipelineApiError(httpResponse, err))
+		r.rollback(ctx, resp, created)
+		return
+	}
+
+	destinationMember := pipelineMember{ResourceType: "DataDestination", ResourceId: createDestinationResponse.ResourceId}
+	created = append(created, destinationMember)
+	tflog.Info(ctx, "Created pipeline DataDestination "+destinationMember.ResourceId+", waiting for it to be READY")
+
+	if destination.State, err = r.waitForMemberReady(ctx, destinationMember); err != nil {
+		resp.Diagnostics.AddError("Error creating pipeline DataDestination", err.Error())
+		r.rollback(ctx, resp, created)
+		return
+	}
+	destination.ResourceId = types.StringValue(destinationMember.ResourceId)
+
+	// Map the created members back to the plan and save it
+	resp.Diagnostics.Append(plan.setMembers(ctx, source, filters, destination)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *pipelineResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data pipelineResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	var source pipelineSourceModel
+	var filters []pipelineFilterModel
+	var destination pipelineDestinationModel
+	resp.Diagnostics.Append(data.Source.As(ctx, &source, basetypes.ObjectAsOptions{})...)
+	resp.Diagnostics.Append(data.Filters.ElementsAs(ctx, &filters, false)...)
+	resp.Diagnostics.Append(data.Destination.As(ctx, &destination, basetypes.ObjectAsOptions{})...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Without its DataSource nothing else in the pipeline can work, so treat the pipeline as deleted.
+	var describeDataSource Ambar.DescribeResourceRequest
+	describeDataSource.ResourceId = source.ResourceId.ValueString()
+
+	describeSourceResponse, httpResponse, err := r.client.AmbarAPI.DescribeDataSource(ctx).DescribeResourceRequest(describeDataSource).Execute()
+	if err != nil {
+		if httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound {
+			tflog.Info(ctx, "Pipeline DataSource was not found. Removing from state.")
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError("Unable to read Pipeline resource.", err.Error())
+		return
+	}
+
+	if describeSourceResponse.State == "DELETING" {
+		tflog.Info(ctx, "Pipeline DataSource was found in DELETING state and will not exist eventually. Removing from state.")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	source.State = types.StringValue(describeSourceResponse.State)
+	source.Description = types.StringPointerValue(withoutOwnershipTag(describeSourceResponse.Description))
+
+	// Filters and the DataDestination missing from Ambar are marked as DELETED, so the pipeline is replaced during the
+	// next plan rather than leaving the remaining members behind.
+	for i := range filters {
+		var describeFilter Ambar.DescribeResourceRequest
+		describeFilter.ResourceId = filters[i].ResourceId.ValueString()
+
+		describeFilterResponse, httpResponse, err := r.client.AmbarAPI.DescribeFilter(ctx).DescribeResourceRequest(describeFilter).Execute()
+		if err != nil {
+			if httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound {
+				tflog.Info(ctx, "Pipeline Filter "+describeFilter.ResourceId+" was not found, marking it as DELETED.")
+				filters[i].State = types.StringValue("DELETED")
+				continue
+			}
+
+			resp.Diagnostics.AddError("Unable to read Pipeline resource.", err.Error())
+			return
+		}
+
+		filters[i].State = types.StringValue(describeFilterResponse.State)
+		filters[i].Description = types.StringPointerValue(withoutOwnershipTag(describeFilterResponse.Description))
+	}
+
+	var describeDataDestination Ambar.DescribeResourceRequest
+	describeDataDestination.ResourceId = destination.ResourceId.ValueString()
+
+	describeDestinationResponse, httpResponse, err := r.client.AmbarAPI.DescribeDataDestination(ctx).DescribeResourceRequest(describeDataDestination).Execute()
+	switch {
+	case err == nil:
+		destination.State = types.StringValue(describeDestinationResponse.State)
+		destination.Description = types.StringPointerValue(withoutOwnershipTag(describeDestinationResponse.Description))
+		destination.DestinationEndpoint = types.StringValue(describeDestinationResponse.DestinationEndpoint)
+	case httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound:
+		tflog.Info(ctx, "Pipeline DataDestination "+describeDataDestination.ResourceId+" was not found, marking it as DELETED.")
+		destination.State = types.StringValue("DELETED")
+	default:
+		resp.Diagnostics.AddError("Unable to read Pipeline resource.", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(data.setMembers(ctx, source, filters, destination)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *pipelineResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "update", "pipeline") {
+		return
+	}
+
+	// Every change to a member requires the pipeline to be replaced, so there is nothing to update in Ambar.
+	var data pipelineResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *pipelineResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if checkReadOnly(&resp.Diagnostics, r.readOnly, "delete", "pipeline") {
+		return
+	}
+
+	var data pipelineResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	var source pipelineSourceModel
+	var filters []pipelineFilterModel
+	var destination pipelineDestinationModel
+	resp.Diagnostics.Append(data.Source.As(ctx, &source, basetypes.ObjectAsOptions{})...)
+	resp.Diagnostics.Append(data.Filters.ElementsAs(ctx, &filters, false)...)
+	resp.Diagnostics.Append(data.Destination.As(ctx, &destination, basetypes.ObjectAsOptions{})...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Members are listed in creation order, and deleted in reverse.
+	members := []pipelineMember{{ResourceType: "DataSource", ResourceId: source.ResourceId.ValueString()}}
+	for _, filter := range filters {
+		members = append(members, pipelineMember{ResourceType: "Filter", ResourceId: filter.ResourceId.ValueString()})
+	}
+	members = append(members, pipelineMember{ResourceType: "DataDestination", ResourceId: destination.ResourceId.ValueString()})
+
+	if failures := r.deleteMembers(ctx, members); len(failures) > 0 {
+		resp.Diagnostics.AddError("Unable to delete Pipeline resource.", strings.Join(failures, "\n"))
+	}
+}
+
+// setMembers stores the pipeline members back into the model.
+func (m *pipelineResourceModel) setMembers(ctx context.Context, source pipelineSourceModel, filters []pipelineFilterModel, destination pipelineDestinationModel) (diags diag.Diagnostics) {
+	var d diag.Diagnostics
+
+	m.Source, d = types.ObjectValueFrom(ctx, pipelineSourceAttrTypes, source)
+	diags.Append(d...)
+
+	m.Filters, d = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: pipelineFilterAttrTypes}, filters)
+	diags.Append(d...)
+
+	m.Destination, d = types.ObjectValueFrom(ctx, pipelineDestinationAttrTypes, destination)
+	diags.Append(d...)
+
+	return diags
+}
+
+// rollback deletes the members created so far after a failed create. Members which could not be deleted are reported,
+// as Terraform will not track them once the create has failed.
+func (r *pipelineResource) rollback(ctx context.Context, resp *resource.CreateResponse, created []pipelineMember) {
+	// Interrupting the apply cancels ctx, and is the most common reason to roll back, so the members are deleted with a
+	// context which is not cancelled along with it.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), pipelineRollbackTimeout)
+	defer cancel()
+
+	tflog.Info(ctx, "Pipeline creation failed, deleting the "+strconv.Itoa(len(created))+" members created so far")
+
+	if failures := r.deleteMembers(ctx, created); len(failures) > 0 {
+		resp.Diagnostics.AddError(
+			"Unable to roll back pipeline",
+			"The following resources were created for the pipeline but could not be deleted, and must be deleted manually:\n"+strings.Join(failures, "\n"),
+		)
+	}
+}
+
+// deleteMembers deletes the members in reverse order, waiting for each to be deleted before moving on so Ambar does
+// not reject deleting a resource which is still in use. It returns a description of each member which failed to delete.
+func (r *pipelineResource) deleteMembers(ctx context.Context, members []pipelineMember) []string {
+	var failures []string
+
+	for i := len(members) - 1; i >= 0; i-- {
+		member := members[i]
+		if member.ResourceId == "" {
+			continue
+		}
+
+		deleteRequest := Ambar.DeleteResourceRequest{ResourceId: member.ResourceId}
+
+		var httpResponse *http.Response
+		var err error
+		switch member.ResourceType {
+		case "DataSource":
+			_, httpResponse, err = r.client.AmbarAPI.DeleteDataSource(ctx).DeleteResourceRequest(deleteRequest).Execute()
+		case "Filter":
+			_, httpResponse, err = r.client.AmbarAPI.DeleteFilter(ctx).DeleteResourceRequest(deleteRequest).Execute()
+		default:
+			_, httpResponse, err = r.client.AmbarAPI.DeleteDataDestination(ctx).DeleteResourceRequest(deleteRequest).Execute()
+		}
+
+		if err != nil {
+			if httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound {
+				tflog.Info(ctx, member.ResourceType+" "+member.ResourceId+" was not found, nothing to delete.")
+				continue
+			}
+
+			failures = append(failures, fmt.Sprintf("%s %s: %s", member.ResourceType, member.ResourceId, pipelineApiError(httpResponse, err)))
+			continue
+		}
+
+		if err := r.waitForMemberDeleted(ctx, member); err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %s", member.ResourceType, member.ResourceId, err.Error()))
+		}
+	}
+
+	return failures
+}
+
+// describeMemberState returns the current state of a pipeline member.
+func (r *pipelineResource) describeMemberState(ctx context.Context, member pipelineMember) (string, *http.Response, error) {
+	describeRequest := Ambar.DescribeResourceRequest{ResourceId: member.ResourceId}
+
+	switch member.ResourceType {
+	case "DataSource":
+		describeResourceResponse, httpResponse, err := r.client.AmbarAPI.DescribeDataSource(ctx).DescribeResourceRequest(describeRequest).Execute()
+		if err != nil {
+			return "", httpResponse, err
+		}
+		return describeResourceResponse.State, httpResponse, nil
+	case "Filter":
+		describeResourceResponse, httpResponse, err := r.client.AmbarAPI.DescribeFilter(ctx).DescribeResourceRequest(describeRequest).Execute()
+		if err != nil {
+			return "", httpResponse, err
+		}
+		return describeResourceResponse.State, httpResponse, nil
+	default:
+		describeResourceResponse, httpResponse, err := r.client.AmbarAPI.DescribeDataDestination(ctx).DescribeResourceRequest(describeRequest).Execute()
+		if err != nil {
+			return "", httpResponse, err
+		}
+		return describeResourceResponse.State, httpResponse, nil
+	}
+}
+
+// waitForMemberReady waits for a newly created member to be READY, returning its final state.
+func (r *pipelineResource) waitForMemberReady(ctx context.Context, member pipelineMember) (types.String, error) {
+	for {
+		if err := waitForPoll(ctx); err != nil {
+			return types.StringNull(), err
+		}
+
+		state, _, err := r.describeMemberState(ctx, member)
+		if err != nil {
+			return types.StringNull(), fmt.Errorf("unable to describe %s %s: %w", member.ResourceType, member.ResourceId, err)
+		}
+
+		tflog.Debug(ctx, "Got state for "+member.ResourceType+" "+member.ResourceId+": "+state)
+		switch state {
+		case "READY":
+			return types.StringValue(state), nil
+		case "FAILED":
+			return types.StringNull(), fmt.Errorf("%s %s is in the FAILED state, indicating errors while creating it with the configured values", member.ResourceType, member.ResourceId)
+		}
+	}
+}
+
+// waitForMemberDeleted waits until a member can no longer be described.
+func (r *pipelineResource) waitForMemberDeleted(ctx context.Context, member pipelineMember) error {
+	for {
+		if err := waitForPoll(ctx); err != nil {
+			return err
+		}
+
+		state, httpResponse, err := r.describeMemberState(ctx, member)
+		if err != nil {
+			if httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound {
+				return nil
+			}
+			return fmt.Errorf("unable to describe %s to confirm deletion: %w", member.ResourceType, err)
+		}
+
+		tflog.Debug(ctx, "Waiting for "+member.ResourceType+" "+member.ResourceId+" to complete deletion. Current state: "+state)
+	}
+}
+
+// waitForPoll sleeps for the poll interval, returning early if Terraform cancels the operation.
+func waitForPoll(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return errors.New("interrupted while waiting for Ambar: " + ctx.Err().Error())
+	case <-time.After(pipelinePollInterval):
+		return nil
+	}
+}
+
+// pipelineApiError extracts the error from the body of an Ambar API response, falling back to the client error.
+func pipelineApiError(httpResponse *http.Response, err error) string {
+	if httpResponse != nil && httpResponse.Body != nil {
+		if httpBody, readErr := io.ReadAll(httpResponse.Body); readErr == nil && len(httpBody) > 0 {
+			return AmbarApiErrorToTerraformErrorString(string(httpBody))
+		}
+	}
+
+	if err != nil {
+		return err.Error()
+	}
+	return "unexpected empty response"
+}
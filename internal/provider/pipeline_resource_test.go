@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+const (
+	examplePipelineResourceConfig = `
+resource "ambar_pipeline" "test_pipeline" {
+  source {
+    data_source_type = "postgres"
+    description = "My Terraform Acceptance Test Pipeline"
+    data_source_config = {
+      "hostname": "hostname",
+      "hostPort": "5432",
+      "databaseName": "postgres",
+      "tableName": "events",
+      "publicationName": "acceptance_test_pipeline_pub",
+      "columns": "partitioning_column,serial_column,columns",
+      "partitioning_column": "partitioning_column",
+      "serial_column": "serial_column",
+      "username": "username",
+      "password": "password"
+    }
+  }
+  filter {
+    description = "My test Pipeline Filter"
+    filter_contents = "lookup(\"columns\") == \"value\""
+  }
+  destination {
+    description = "My Terraform Pipeline DataDestination"
+    destination_endpoint = "https://1.2.3.4.com/data"
+    username = "username"
+    password = "password"
+  }
+}`
+)
+
+func TestAmbarPipelineResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + examplePipelineResourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ambar_pipeline.test_pipeline", "source.resource_id"),
+					resource.TestCheckResourceAttrSet("ambar_pipeline.test_pipeline", "filter.0.resource_id"),
+					resource.TestCheckResourceAttrSet("ambar_pipeline.test_pipeline", "destination.resource_id"),
+					resource.TestCheckResourceAttr("ambar_pipeline.test_pipeline", "destination.state", "READY"),
+				),
+			},
+		},
+	})
+}
+
+func TestPipelineCreateRollsBackWhenCancelled(t *testing.T) {
+	previousPollInterval := pipelinePollInterval
+	pipelinePollInterval = time.Millisecond
+	t.Cleanup(func() { pipelinePollInterval = previousPollInterval })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The apply is interrupted while waiting for the DataSource to be READY.
+	var mu sync.Mutex
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/source":
+			w.Write([]byte(`{"resourceId":"source-1","state":"CREATING"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/source":
+			deleted = true
+			w.Write([]byte(`{"resourceId":"source-1","state":"DELETING"}`))
+		case deleted:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"not found"}`))
+		default:
+			cancel()
+			w.Write([]byte(`{"resourceId":"source-1","state":"CREATING"}`))
+		}
+	}))
+	defer server.Close()
+
+	r := &pipelineResource{client: testAmbarClient(server)}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	schemaType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	var model pipelineResourceModel
+	diags := model.setMembers(ctx,
+		pipelineSourceModel{
+			DataSourceType:   types.StringValue("postgres"),
+			Description:      types.StringValue("source"),
+			DataSourceConfig: types.MapValueMust(types.StringType, map[string]attr.Value{"hostname": types.StringValue("hostname")}),
+			State:            types.StringUnknown(),
+			ResourceId:       types.StringUnknown(),
+		},
+		[]pipelineFilterModel{},
+		pipelineDestinationModel{
+			Description:         types.StringValue("destination"),
+			DestinationEndpoint: types.StringValue("https://example.com/data"),
+			Username:            types.StringValue("username"),
+			Password:            types.StringValue("password"),
+			State:               types.StringUnknown(),
+			ResourceId:          types.StringUnknown(),
+		},
+	)
+	plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaType, nil)}
+	diags.Append(plan.Set(ctx, &model)...)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics building the plan: %v", diags)
+	}
+
+	resp := fwresource.CreateResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaType, nil)}}
+	r.Create(ctx, fwresource.CreateRequest{Plan: plan}, &resp)
+
+	if !deleted {
+		t.Fatal("expected the DataSource created before the cancellation to be deleted")
+	}
+	for _, d := range resp.Diagnostics.Errors() {
+		if d.Summary() == "Unable to roll back pipeline" {
+			t.Errorf("expected the rollback to succeed, got: %s", d.Detail())
+		}
+	}
+	if !resp.Diagnostics.HasError() {
+		t.Error("expected the cancelled create to fail")
+	}
+}
@@ -5,20 +5,29 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	Ambar "github.com/ambarltd/ambar_go_client"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure ambarProvider satisfies various provider interfaces.
 var _ provider.Provider = &ambarProvider{}
+var _ provider.ProviderWithFunctions = &ambarProvider{}
+var _ provider.ProviderWithValidateConfig = &ambarProvider{}
 
 // ambarProvider defines the provider implementation.
 type ambarProvider struct {
@@ -30,8 +39,42 @@ type ambarProvider struct {
 
 // ambarProviderModel describes the provider data model.
 type ambarProviderModel struct {
-	Endpoint types.String `tfsdk:"endpoint"`
-	Api_key  types.String `tfsdk:"api_key"`
+	Endpoint                types.String                  `tfsdk:"endpoint"`
+	Region                  types.String                  `tfsdk:"region"`
+	Api_key                 types.String                  `tfsdk:"api_key"`
+	Profile                 types.String                  `tfsdk:"profile"`
+	CredentialProcess       types.List                    `tfsdk:"credential_process"`
+	FilterContentsSensitive types.Bool                    `tfsdk:"filter_contents_sensitive"`
+	OwnershipTag            types.String                  `tfsdk:"ownership_tag"`
+	ValidateCredentials     types.Bool                    `tfsdk:"validate_credentials"`
+	ProxyUrl                types.String                  `tfsdk:"proxy_url"`
+	CaCertPem               types.String                  `tfsdk:"ca_cert_pem"`
+	CaCertFile              types.String                  `tfsdk:"ca_cert_file"`
+	InsecureSkipVerify      types.Bool                    `tfsdk:"insecure_skip_verify"`
+	RequestTimeout          types.String                  `tfsdk:"request_timeout"`
+	AuditLogPath            types.String                  `tfsdk:"audit_log_path"`
+	ReadOnly                types.Bool                    `tfsdk:"read_only"`
+	UserAgentSuffix         types.String                  `tfsdk:"user_agent_suffix"`
+	DefaultDescription      *ambarDefaultDescriptionModel `tfsdk:"default_description"`
+}
+
+// ambarDefaultDescriptionModel describes the default_description block of the provider.
+type ambarDefaultDescriptionModel struct {
+	Prefix types.String `tfsdk:"prefix"`
+	Suffix types.String `tfsdk:"suffix"`
+}
+
+// ambarProviderData is made available to resources and data sources once the provider is configured.
+type ambarProviderData struct {
+	Client *Ambar.APIClient
+	// FilterContentsSensitive is used by ambar_filter resources which do not set filter_contents_sensitive themselves.
+	FilterContentsSensitive bool
+	// OwnershipTag is appended to the description of every resource the provider creates, when set.
+	OwnershipTag string
+	// DefaultDescription decorates the description of every DataSource, Filter and DataDestination the provider creates.
+	DefaultDescription descriptionDecoration
+	// ReadOnly makes every Create, Update and Delete fail before calling Ambar.
+	ReadOnly bool
 }
 
 func (p *ambarProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -44,21 +87,124 @@ func (p *ambarProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 		MarkdownDescription: "Interact with your regional Ambar environment.",
 		Description:         "Interact with your regional Ambar environment.",
 		Attributes: map[string]schema.Attribute{
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "The name of a profile in the shared credentials file, `~/.ambar/credentials`, holding the `endpoint` and `api_key` to use. May also be provided via the AMBAR_PROFILE environment variable. Settings are looked up in order from the `endpoint` and `api_key` attributes, then the `credential_process`, then the AMBAR_ENDPOINT and AMBAR_ENVIRONMENT_KEY environment variables, and then the profile.",
+				Description:         "The name of a profile in the shared credentials file, ~/.ambar/credentials, holding the endpoint and api_key to use. May also be provided via the AMBAR_PROFILE environment variable. Settings are looked up in order from the endpoint and api_key attributes, then the credential_process, then the AMBAR_ENDPOINT and AMBAR_ENVIRONMENT_KEY environment variables, and then the profile.",
+				Optional:            true,
+			},
+			"credential_process": schema.ListAttribute{
+				MarkdownDescription: "A command and its arguments, such as `[\"/usr/local/bin/ambar-broker\", \"payments-prod\"]`, which the provider runs to fetch its credentials when configured. The command must write a JSON object to stdout with an `api_key`, and optionally an `endpoint` and an RFC 3339 `expiration` time. Anything written to stderr is shown as a warning, or as part of the error when the command fails. The `endpoint` and `api_key` attributes take precedence over the output.",
+				Description:         "A command and its arguments, such as [\"/usr/local/bin/ambar-broker\", \"payments-prod\"], which the provider runs to fetch its credentials when configured. The command must write a JSON object to stdout with an api_key, and optionally an endpoint and an RFC 3339 expiration time. Anything written to stderr is shown as a warning, or as part of the error when the command fails. The endpoint and api_key attributes take precedence over the output.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
 			"endpoint": schema.StringAttribute{
-				MarkdownDescription: "The Ambar API URI to use for these resources. Note that Ambar has region specific endpoints, so be sure to set this to the region your key was created in. May also be provided via the AMBAR_ENDPOINT environment variable",
-				Description:         "The Ambar API URI to use for these resources. Note that Ambar has region specific endpoints, so be sure to set this to the region your key was created in. May also be provided via the AMBAR_ENDPOINT environment variable",
-				Required:            true,
+				MarkdownDescription: "The Ambar API URI to use for these resources. Note that Ambar has region specific endpoints, so be sure to set this to the region your key was created in. May be a host such as `euw1.api.ambar.cloud`, which is used over HTTPS, or a full URL with a scheme and base path, such as `https://gateway.example.com/ambar`. May also be provided via the AMBAR_ENDPOINT environment variable, which is used when this is not set.",
+				Description:         "The Ambar API URI to use for these resources. Note that Ambar has region specific endpoints, so be sure to set this to the region your key was created in. May be a host such as euw1.api.ambar.cloud, which is used over HTTPS, or a full URL with a scheme and base path, such as https://gateway.example.com/ambar. May also be provided via the AMBAR_ENDPOINT environment variable, which is used when this is not set.",
+				Optional:            true,
+			},
+			"region": schema.StringAttribute{
+				MarkdownDescription: "The Ambar region your key was created in, such as `euw1`, used instead of `endpoint` to select the region specific API endpoint. May also be provided via the AMBAR_REGION environment variable, which is used when no endpoint is set in any other way. Conflicts with `endpoint`.",
+				Description:         "The Ambar region your key was created in, such as euw1, used instead of endpoint to select the region specific API endpoint. May also be provided via the AMBAR_REGION environment variable, which is used when no endpoint is set in any other way. Conflicts with endpoint.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringOneOf(ambarRegions()...),
+				},
 			},
 			"api_key": schema.StringAttribute{
-				MarkdownDescription: "The API Key for your Ambar environment. Keys are region specific, so make sure to use a key which is valid for the selected Ambar endpoint. May also be provided via the AMBAR_ENVIRONMENT_KEY environment variable",
-				Description:         "The API Key for your Ambar environment. Keys are region specific, so make sure to use a key which is valid for the selected Ambar endpoint. May also be provided via the AMBAR_ENVIRONMENT_KEY environment variable",
-				Required:            true,
+				MarkdownDescription: "The API Key for your Ambar environment. Keys are region specific, so make sure to use a key which is valid for the selected Ambar endpoint. May also be provided via the AMBAR_ENVIRONMENT_KEY environment variable, which is used when this is not set.",
+				Description:         "The API Key for your Ambar environment. Keys are region specific, so make sure to use a key which is valid for the selected Ambar endpoint. May also be provided via the AMBAR_ENVIRONMENT_KEY environment variable, which is used when this is not set.",
+				Optional:            true,
 				Sensitive:           true,
 			},
+			"filter_contents_sensitive": schema.BoolAttribute{
+				MarkdownDescription: "The default for the `filter_contents_sensitive` attribute of `ambar_filter` resources. Defaults to `true`. Set to `false` when your filters do not contain secrets, so that plans show a readable diff of filter changes.",
+				Description:         "The default for the filter_contents_sensitive attribute of ambar_filter resources. Defaults to true. Set to false when your filters do not contain secrets, so that plans show a readable diff of filter changes.",
+				Optional:            true,
+			},
+			"validate_credentials": schema.BoolAttribute{
+				MarkdownDescription: "Whether to check the endpoint and API key with one cheap authenticated call when the provider is configured, reporting an unreachable endpoint, an invalid key or a key for a different region before any resource is changed. Defaults to `true`.",
+				Description:         "Whether to check the endpoint and API key with one cheap authenticated call when the provider is configured, reporting an unreachable endpoint, an invalid key or a key for a different region before any resource is changed. Defaults to true.",
+				Optional:            true,
+			},
+			"proxy_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of a proxy to send all Ambar API requests through, such as `http://proxy.example.com:3128`. Supports the `http`, `https` and `socks5` schemes. Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.",
+				Description:         "The URL of a proxy to send all Ambar API requests through, such as http://proxy.example.com:3128. Supports the http, https and socks5 schemes. Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.",
+				Optional:            true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA certificates to trust in addition to the system certificates, such as the CA of a TLS-inspecting gateway.",
+				Description:         "PEM encoded CA certificates to trust in addition to the system certificates, such as the CA of a TLS-inspecting gateway.",
+				Optional:            true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				MarkdownDescription: "The path of a file holding PEM encoded CA certificates to trust in addition to the system certificates.",
+				Description:         "The path of a file holding PEM encoded CA certificates to trust in addition to the system certificates.",
+				Optional:            true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				MarkdownDescription: "Disables verification of the Ambar API's TLS certificate, exposing your API key to anyone able to intercept the connection. Only use this against local mocks, and prefer `ca_cert_pem` or `ca_cert_file` otherwise. Defaults to `false`.",
+				Description:         "Disables verification of the Ambar API's TLS certificate, exposing your API key to anyone able to intercept the connection. Only use this against local mocks, and prefer ca_cert_pem or ca_cert_file otherwise. Defaults to false.",
+				Optional:            true,
+			},
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: "The time to wait for each Ambar API request, as a duration such as `30s` or `2m`. Requests do not time out by default.",
+				Description:         "The time to wait for each Ambar API request, as a duration such as 30s or 2m. Requests do not time out by default.",
+				Optional:            true,
+			},
+			"audit_log_path": schema.StringAttribute{
+				MarkdownDescription: "The path of a file the provider appends a JSON line to for every Ambar API call which creates, updates or deletes a resource, recording the time, operation, resource type and id, request fields, outcome and duration. Credentials and filter contents are redacted. The file is created when it does not exist.",
+				Description:         "The path of a file the provider appends a JSON line to for every Ambar API call which creates, updates or deletes a resource, recording the time, operation, resource type and id, request fields, outcome and duration. Credentials and filter contents are redacted. The file is created when it does not exist.",
+				Optional:            true,
+			},
+			"read_only": schema.BoolAttribute{
+				MarkdownDescription: "Prevents the provider from changing Ambar resources, such as for drift detection pipelines using production keys. Every create, update and delete fails before calling Ambar, while reading, importing and data sources keep working. May also be provided via the AMBAR_READ_ONLY environment variable. Defaults to `false`.",
+				Description:         "Prevents the provider from changing Ambar resources, such as for drift detection pipelines using production keys. Every create, update and delete fails before calling Ambar, while reading, importing and data sources keep working. May also be provided via the AMBAR_READ_ONLY environment variable. Defaults to false.",
+				Optional:            true,
+			},
+			"user_agent_suffix": schema.StringAttribute{
+				MarkdownDescription: "Text appended to the User-Agent sent with every Ambar API request, such as the name of the pipeline running Terraform. The User-Agent always includes the provider and Terraform versions.",
+				Description:         "Text appended to the User-Agent sent with every Ambar API request, such as the name of the pipeline running Terraform. The User-Agent always includes the provider and Terraform versions.",
+				Optional:            true,
+			},
+			"ownership_tag": schema.StringAttribute{
+				MarkdownDescription: "A tag identifying this Terraform configuration, such as `payments-prod`. When set, the provider appends `[terraform-owner:<tag>]` to the description of every Ambar resource it creates, and removes it again when reading, so resources can be traced back to the configuration owning them. Use the `ambar_unmanaged_resources` data source to list resources without the tag. May contain letters, digits and `_.:/@-`, up to 128 characters.",
+				Description:         "A tag identifying this Terraform configuration, such as payments-prod. When set, the provider appends [terraform-owner:<tag>] to the description of every Ambar resource it creates, and removes it again when reading, so resources can be traced back to the configuration owning them. Use the ambar_unmanaged_resources data source to list resources without the tag. May contain letters, digits and _.:/@-, up to 128 characters.",
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"default_description": schema.SingleNestedBlock{
+				MarkdownDescription: "Text added to the description of every `ambar_data_source`, `ambar_filter` and `ambar_data_destination` the provider creates, such as the environment name. The prefix and suffix may use the template variables `{workspace}`, the selected Terraform workspace, and `{resource_type}`, the Terraform resource type. Terraform does not share module addresses with providers, so use `path.module` or other expressions in the value where needed. The decoration is removed again when reading resources, so it never shows up in plans. Descriptions can not be updated in place, so changing the decoration only applies to resources created afterwards.",
+				Description:         "Text added to the description of every ambar_data_source, ambar_filter and ambar_data_destination the provider creates, such as the environment name. The prefix and suffix may use the template variables {workspace}, the selected Terraform workspace, and {resource_type}, the Terraform resource type. Terraform does not share module addresses with providers, so use path.module or other expressions in the value where needed. The decoration is removed again when reading resources, so it never shows up in plans. Descriptions can not be updated in place, so changing the decoration only applies to resources created afterwards.",
+				Attributes: map[string]schema.Attribute{
+					"prefix": schema.StringAttribute{
+						MarkdownDescription: "Text added before each description, such as `\"[{workspace}] \"`.",
+						Description:         "Text added before each description, such as \"[{workspace}] \".",
+						Optional:            true,
+					},
+					"suffix": schema.StringAttribute{
+						MarkdownDescription: "Text added after each description, such as `\" ({resource_type})\"`.",
+						Description:         "Text added after each description, such as \" ({resource_type})\".",
+						Optional:            true,
+					},
+				},
+			},
 		},
 	}
 }
 
+// hasUnknownSettings reports whether any setting which must be known to configure the provider is unknown.
+// validate_credentials and filter_contents_sensitive fall back to their defaults when unknown, so are not included.
+func (m ambarProviderModel) hasUnknownSettings() bool {
+	return m.Endpoint.IsUnknown() || m.Region.IsUnknown() || m.Api_key.IsUnknown() || m.Profile.IsUnknown() ||
+		m.CredentialProcess.IsUnknown() || containsUnknown(m.CredentialProcess.Elements()) ||
+		m.ProxyUrl.IsUnknown() || m.CaCertPem.IsUnknown() || m.CaCertFile.IsUnknown() || m.InsecureSkipVerify.IsUnknown() ||
+		m.RequestTimeout.IsUnknown() || m.AuditLogPath.IsUnknown() || m.ReadOnly.IsUnknown() || m.UserAgentSuffix.IsUnknown() ||
+		m.OwnershipTag.IsUnknown() ||
+		(m.DefaultDescription != nil && (m.DefaultDescription.Prefix.IsUnknown() || m.DefaultDescription.Suffix.IsUnknown()))
+}
+
 func (p *ambarProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	tflog.Info(ctx, "Configuring Ambar client")
 	// Retrieve provider data from configuration
@@ -69,26 +215,129 @@ func (p *ambarProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		return
 	}
 
+	// Configuration which depends on values from other resources in the same run, such as a key created by another
+	// provider, is unknown until those are applied. Terraform versions supporting deferred actions can plan everything
+	// else first, and come back to this provider's resources in a later round. Other versions get the errors below.
+	if config.hasUnknownSettings() && req.ClientCapabilities.DeferralAllowed {
+		tflog.Info(ctx, "Deferring Ambar resources, as the provider configuration is not yet known")
+		resp.Deferred = &provider.Deferred{
+			Reason: provider.DeferredReasonProviderConfigUnknown,
+		}
+		return
+	}
+
 	// If practitioner provided a configuration value for any of the
 	// attributes, it must be a known value.
 	if config.Endpoint.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("endpoint"),
-			"Unknown Ambar endpoint.",
+			"Unknown Ambar API endpoint",
 			"The provider cannot create the Ambar API client as there is an unknown configuration value for the Ambar API endpoint. "+
 				"Either target apply the source of the value first, set the value statically in the configuration, or use the AMBAR_ENDPOINT environment variable.",
 		)
 	}
 
+	if config.Region.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("region"),
+			"Unknown Ambar region",
+			"The provider cannot create the Ambar API client as there is an unknown configuration value for the Ambar region. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the AMBAR_REGION environment variable.",
+		)
+	}
+
 	if config.Api_key.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("api_key"),
-			"Unknown Ambar API Key",
-			"The provider cannot create the Ambar API client as there is an unknown configuration value for the Ambar API Key. "+
+			"Unknown Ambar API key",
+			"The provider cannot create the Ambar API client as there is an unknown configuration value for the Ambar API key. "+
 				"Either target apply the source of the value first, set the value statically in the configuration, or use the AMBAR_ENVIRONMENT_KEY environment variable.",
 		)
 	}
 
+	if config.Profile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Unknown Ambar profile",
+			"The provider cannot create the Ambar API client as there is an unknown configuration value for the Ambar profile. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the AMBAR_PROFILE environment variable.",
+		)
+	}
+
+	if config.CredentialProcess.IsUnknown() || containsUnknown(config.CredentialProcess.Elements()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("credential_process"),
+			"Unknown Ambar credential process",
+			"The provider cannot create the Ambar API client as there is an unknown configuration value for the Ambar credential process. "+
+				"Either target apply the source of the value first, or set the value statically in the configuration.",
+		)
+	}
+
+	for _, attribute := range []struct {
+		name    string
+		unknown bool
+	}{
+		{"proxy_url", config.ProxyUrl.IsUnknown()},
+		{"ca_cert_pem", config.CaCertPem.IsUnknown()},
+		{"ca_cert_file", config.CaCertFile.IsUnknown()},
+		{"insecure_skip_verify", config.InsecureSkipVerify.IsUnknown()},
+		{"request_timeout", config.RequestTimeout.IsUnknown()},
+	} {
+		if attribute.unknown {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attribute.name),
+				"Unknown Ambar HTTP client setting",
+				"The provider cannot create the Ambar API client as there is an unknown configuration value for "+attribute.name+". "+
+					"Either target apply the source of the value first, or set the value statically in the configuration.",
+			)
+		}
+	}
+
+	if config.AuditLogPath.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("audit_log_path"),
+			"Unknown Ambar audit log path",
+			"The provider cannot record its changes as there is an unknown configuration value for the audit log path. "+
+				"Either target apply the source of the value first, or set the value statically in the configuration.",
+		)
+	}
+
+	if config.ReadOnly.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("read_only"),
+			"Unknown Ambar read-only mode",
+			"The provider cannot tell whether it may change resources as there is an unknown configuration value for read_only. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the AMBAR_READ_ONLY environment variable.",
+		)
+	}
+
+	if config.UserAgentSuffix.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("user_agent_suffix"),
+			"Unknown Ambar User-Agent suffix",
+			"The provider cannot create the Ambar API client as there is an unknown configuration value for the User-Agent suffix. "+
+				"Either target apply the source of the value first, or set the value statically in the configuration.",
+		)
+	}
+
+	if config.OwnershipTag.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ownership_tag"),
+			"Unknown Ambar ownership tag",
+			"The provider cannot tag the resources it creates as there is an unknown configuration value for the ownership tag. "+
+				"Either target apply the source of the value first, or set the value statically in the configuration.",
+		)
+	}
+
+	if config.DefaultDescription != nil && (config.DefaultDescription.Prefix.IsUnknown() || config.DefaultDescription.Suffix.IsUnknown()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("default_description"),
+			"Unknown Ambar default description",
+			"The provider cannot decorate the descriptions of the resources it creates as there is an unknown configuration value for the default description. "+
+				"Either target apply the source of the value first, or set the value statically in the configuration.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -99,14 +348,92 @@ func (p *ambarProvider) Configure(ctx context.Context, req provider.ConfigureReq
 	endpoint := os.Getenv("AMBAR_ENDPOINT")
 	api_key := os.Getenv("AMBAR_ENVIRONMENT_KEY")
 
+	if !config.CredentialProcess.IsNull() {
+		var command []string
+		resp.Diagnostics.Append(config.CredentialProcess.ElementsAs(ctx, &command, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		credentials, stderr, err := runCredentialProcess(ctx, command)
+		if err != nil {
+			detail := "The provider cannot fetch Ambar credentials from the credential process: " + err.Error()
+			if stderr != "" {
+				detail += "\n\nThe credential process wrote to stderr:\n" + stderr
+			}
+			resp.Diagnostics.AddAttributeError(path.Root("credential_process"), "Ambar credential process failed", detail)
+			return
+		}
+
+		if stderr != "" {
+			resp.Diagnostics.AddAttributeWarning(path.Root("credential_process"), "Ambar credential process wrote to stderr", stderr)
+		}
+
+		if credentials.Endpoint != "" {
+			endpoint = credentials.Endpoint
+		}
+		api_key = credentials.ApiKey
+		if credentials.Expiration != nil {
+			tflog.Debug(ctx, "Fetched Ambar credentials expiring at "+credentials.Expiration.Format(time.RFC3339))
+		}
+	}
+
 	if !config.Endpoint.IsNull() {
 		endpoint = config.Endpoint.ValueString()
 	}
 
+	if !config.Region.IsNull() {
+		endpoint = ambarRegionEndpoints[config.Region.ValueString()]
+	}
+
+	// AMBAR_REGION is only a fallback for when no endpoint has been given, so it is not checked otherwise.
+	if region := os.Getenv("AMBAR_REGION"); region != "" && endpoint == "" {
+		var ok bool
+		endpoint, ok = ambarRegionEndpoints[region]
+		if !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("region"),
+				"Invalid Ambar region",
+				"The AMBAR_REGION environment variable is set to the unknown region "+strconv.Quote(region)+". "+
+					"Set it to one of: "+strings.Join(ambarRegions(), ", "),
+			)
+			return
+		}
+	}
+
 	if !config.Api_key.IsNull() {
 		api_key = config.Api_key.ValueString()
 	}
 
+	// Fill in any settings which are still missing from the selected profile of the shared credentials file.
+	profile := os.Getenv("AMBAR_PROFILE")
+	if !config.Profile.IsNull() {
+		profile = config.Profile.ValueString()
+	}
+
+	if profile != "" {
+		credentials, err := sharedCredentialsFile()
+		if err == nil {
+			var settings credentialsProfile
+			settings, err = loadCredentialsProfile(credentials, profile)
+			if endpoint == "" {
+				endpoint = settings.Endpoint
+			}
+			if api_key == "" {
+				api_key = settings.ApiKey
+			}
+		}
+
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("profile"),
+				"Unable to read Ambar profile",
+				"The provider cannot read the Ambar profile "+strconv.Quote(profile)+" from the shared credentials file: "+err.Error(),
+			)
+			return
+		}
+	}
+
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
@@ -115,21 +442,67 @@ func (p *ambarProvider) Configure(ctx context.Context, req provider.ConfigureReq
 			path.Root("endpoint"),
 			"Missing Ambar API endpoint",
 			"The provider cannot create the Ambar API client as there is a missing or empty value for the Ambar API endpoint. "+
-				"Set the endpoint value in the configuration or use the AMBAR_ENDPOINT environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+				"Set the endpoint or region value in the configuration, use the AMBAR_ENDPOINT or AMBAR_REGION environment variables, or select a profile with an endpoint. "+
+				"If any of these is already set, ensure the value is not empty.",
 		)
 	}
 
 	if api_key == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("api_key"),
-			"Missing Ambar API Key",
-			"The provider cannot create the Ambar API client as there is a missing or empty value for the Ambar API Key. "+
-				"Set the key value in the configuration or use the AMBAR_ENVIRONMENT_KEY environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+			"Missing Ambar API key",
+			"The provider cannot create the Ambar API client as there is a missing or empty value for the Ambar API key. "+
+				"Set the api_key value in the configuration, use the AMBAR_ENVIRONMENT_KEY environment variable, or select a profile with an api_key. "+
+				"If any of these is already set, ensure the value is not empty.",
+		)
+	}
+
+	if !config.OwnershipTag.IsNull() && !ownershipTagPattern.MatchString(config.OwnershipTag.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ownership_tag"),
+			"Invalid Ambar ownership tag",
+			"The ownership_tag must be between 1 and 128 characters, using only letters, digits and the characters _.:/@- so it can be parsed back out of resource descriptions.",
+		)
+	}
+
+	endpointURL, err := parseEndpoint(endpoint)
+	if endpoint != "" && err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("endpoint"),
+			"Invalid Ambar API endpoint",
+			"The endpoint must be a host such as euw1.api.ambar.cloud, or a URL such as https://gateway.example.com/ambar: "+err.Error(),
 		)
 	}
 
+	httpClientConfig := p.httpClientConfig(config, &resp.Diagnostics)
+
+	readOnly := false
+	if value := os.Getenv("AMBAR_READ_ONLY"); value != "" {
+		readOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("read_only"),
+				"Invalid Ambar read-only mode",
+				"The AMBAR_READ_ONLY environment variable must be true or false, got: "+strconv.Quote(value),
+			)
+		}
+	}
+	if !config.ReadOnly.IsNull() {
+		readOnly = config.ReadOnly.ValueBool()
+	}
+
+	var audit *auditLog
+	if !config.AuditLogPath.IsNull() {
+		audit, err = openAuditLog(config.AuditLogPath.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("audit_log_path"),
+				"Unable to open Ambar audit log",
+				"The provider cannot write to the audit log: "+err.Error(),
+			)
+		}
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -137,32 +510,152 @@ func (p *ambarProvider) Configure(ctx context.Context, req provider.ConfigureReq
 	ctx = tflog.SetField(ctx, "ambar_endpoint", endpoint)
 	ctx = tflog.SetField(ctx, "ambar_environment_key", api_key)
 	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "ambar_environment_key")
+	ctx = tflog.MaskAllFieldValuesStrings(ctx, api_key)
+	ctx = tflog.MaskMessageStrings(ctx, api_key)
 	tflog.Info(ctx, "Creating Ambar client")
 
 	cfg := Ambar.NewConfiguration()
 	cfg.AddDefaultHeader("x-api-key", api_key)
-	cfg.Host = endpoint
+	cfg.UserAgent = userAgent(p.version, req.TerraformVersion, config.UserAgentSuffix.ValueString())
+	cfg.Scheme = endpointURL.Scheme
+	cfg.Host = endpointURL.Host
+	cfg.Servers = Ambar.ServerConfigurations{{URL: endpointURL.String()}}
+	cfg.HTTPClient = newHTTPClient(httpClientConfig)
+	if audit != nil {
+		cfg.HTTPClient.Transport = newAuditTransport(cfg.HTTPClient.Transport, audit)
+	}
+	if readOnly {
+		cfg.HTTPClient.Transport = newReadOnlyTransport(cfg.HTTPClient.Transport)
+	}
+	cfg.HTTPClient.Transport = newLoggingTransport(cfg.HTTPClient.Transport, traceLoggingEnabled())
 
 	client := Ambar.NewAPIClient(cfg)
 
-	// Make the Ambar client available during DataSource and Resource
+	if config.ValidateCredentials.IsNull() || config.ValidateCredentials.IsUnknown() || config.ValidateCredentials.ValueBool() {
+		if !validateCredentials(ctx, client, &resp.Diagnostics, endpoint) {
+			return
+		}
+	}
+
+	providerData := &ambarProviderData{
+		Client:                  client,
+		FilterContentsSensitive: config.FilterContentsSensitive.IsNull() || config.FilterContentsSensitive.IsUnknown() || config.FilterContentsSensitive.ValueBool(),
+		OwnershipTag:            config.OwnershipTag.ValueString(),
+		ReadOnly:                readOnly,
+	}
+
+	if config.DefaultDescription != nil {
+		providerData.DefaultDescription = descriptionDecoration{
+			Prefix: config.DefaultDescription.Prefix.ValueString(),
+			Suffix: config.DefaultDescription.Suffix.ValueString(),
+		}
+	}
+
+	// Make the Ambar client and provider settings available during DataSource and Resource
 	// type Configure methods.
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
 	tflog.Info(ctx, "Configured Ambar client", map[string]any{"success": true})
 }
 
+// httpClientConfig returns the HTTP transport settings of the provider configuration, adding errors for invalid values
+// to diags.
+func (p *ambarProvider) httpClientConfig(config ambarProviderModel, diags *diag.Diagnostics) httpClientConfig {
+	var httpClientConfig httpClientConfig
+
+	if !config.ProxyUrl.IsNull() {
+		proxyURL, err := parseProxyURL(config.ProxyUrl.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("proxy_url"), "Invalid Ambar proxy URL", "The proxy_url must be a URL such as http://proxy.example.com:3128: "+err.Error())
+		}
+		httpClientConfig.ProxyURL = proxyURL
+	}
+
+	if !config.CaCertPem.IsNull() {
+		pem := []byte(config.CaCertPem.ValueString())
+		if err := checkCACertsPEM(pem); err != nil {
+			diags.AddAttributeError(path.Root("ca_cert_pem"), "Invalid Ambar CA certificate", "The ca_cert_pem can not be used: "+err.Error())
+		}
+		httpClientConfig.CACertsPEM = append(httpClientConfig.CACertsPEM, pem)
+	}
+
+	if !config.CaCertFile.IsNull() {
+		pem, err := os.ReadFile(config.CaCertFile.ValueString())
+		if err == nil {
+			err = checkCACertsPEM(pem)
+		}
+		if err != nil {
+			diags.AddAttributeError(path.Root("ca_cert_file"), "Invalid Ambar CA certificate", "The ca_cert_file can not be used: "+err.Error())
+		}
+		httpClientConfig.CACertsPEM = append(httpClientConfig.CACertsPEM, pem)
+	}
+
+	if config.InsecureSkipVerify.ValueBool() {
+		diags.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"Ambar TLS certificate verification is disabled",
+			"insecure_skip_verify is set, so the provider does not check who it is sending your Ambar API key to. "+
+				"Anyone able to intercept the connection can read and use the key. Only use this against local mocks, "+
+				"and use ca_cert_pem or ca_cert_file to trust a gateway's CA instead.",
+		)
+		httpClientConfig.InsecureSkipVerify = true
+	}
+
+	if !config.RequestTimeout.IsNull() {
+		timeout, err := time.ParseDuration(config.RequestTimeout.ValueString())
+		if err == nil && timeout <= 0 {
+			err = errors.New("the timeout must be positive")
+		}
+		if err != nil {
+			diags.AddAttributeError(path.Root("request_timeout"), "Invalid Ambar request timeout", "The request_timeout must be a duration such as 30s or 2m: "+err.Error())
+		}
+		httpClientConfig.RequestTimeout = timeout
+	}
+
+	return httpClientConfig
+}
+
 func (p *ambarProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewDataSourceResource,
 		NewFilterResource,
 		NewDataDestinationResource,
+		NewPipelineResource,
 	}
 }
 
-// DataSources Ambar does not currently have any *Terraform* Data Source resource types, not to be confused with the Ambar DataSource resource type.
+// DataSources returns the *Terraform* Data Sources of the provider, not to be confused with the Ambar DataSource resource type.
 func (p *ambarProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return nil
+	return []func() datasource.DataSource{
+		NewUnmanagedResourcesDataSource,
+	}
+}
+
+func (p *ambarProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var config ambarProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.Endpoint.IsNull() && !config.Region.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("region"),
+			"Conflicting Ambar endpoint and region",
+			"Only one of endpoint and region may be set, as the region selects the endpoint to use.",
+		)
+	}
+}
+
+func (p *ambarProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewFilterMatchesFunction,
+		NewFilterAndFunction,
+		NewFilterOrFunction,
+		NewFilterValidateFunction,
+		NewFilterValidateErrorFunction,
+		NewEndpointForRegionFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
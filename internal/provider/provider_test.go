@@ -4,8 +4,21 @@
 package provider
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 const (
@@ -28,3 +41,317 @@ const (
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
 	"ambar": providerserver.NewProtocol6WithError(New("test")()),
 }
+
+// testProviderConfigure runs Configure with the given provider configuration values. Attributes which are not given
+// are null, as if they were left out of the configuration, except validate_credentials, which is disabled so no calls
+// are made to Ambar.
+func testProviderConfigure(t *testing.T, values map[string]tftypes.Value) *provider.ConfigureResponse {
+	t.Helper()
+	return testProviderConfigureWithCapabilities(t, values, provider.ConfigureProviderClientCapabilities{})
+}
+
+// testProviderConfigureWithCapabilities runs Configure like testProviderConfigure, for a Terraform client with the
+// given capabilities.
+func testProviderConfigureWithCapabilities(t *testing.T, values map[string]tftypes.Value, capabilities provider.ConfigureProviderClientCapabilities) *provider.ConfigureResponse {
+	t.Helper()
+
+	ctx := context.Background()
+	p := New("test")()
+
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		if value, ok := values[name]; ok {
+			attributes[name] = value
+		} else if name == "validate_credentials" {
+			attributes[name] = tftypes.NewValue(tftypes.Bool, false)
+		} else {
+			attributes[name] = tftypes.NewValue(attributeType, nil)
+		}
+	}
+
+	req := provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(objectType, attributes),
+		},
+		ClientCapabilities: capabilities,
+	}
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, req, resp)
+	return resp
+}
+
+func TestProviderConfigureEnvironment(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "region.api.ambar.cloud")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+
+	resp := testProviderConfigure(t, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+	if resp.ResourceData == nil {
+		t.Fatalf("Configure did not set ResourceData")
+	}
+}
+
+func TestProviderConfigureMissing(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "")
+
+	resp := testProviderConfigure(t, nil)
+
+	var summaries []string
+	for _, d := range resp.Diagnostics.Errors() {
+		summaries = append(summaries, d.Summary())
+	}
+	if len(summaries) != 2 || summaries[0] != "Missing Ambar API endpoint" || summaries[1] != "Missing Ambar API key" {
+		t.Errorf("Configure returned errors %q, expected the endpoint and api_key to be missing", summaries)
+	}
+}
+
+func TestProviderConfigureOverridesEnvironment(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+
+	resp := testProviderConfigure(t, map[string]tftypes.Value{
+		"endpoint": tftypes.NewValue(tftypes.String, "region.api.ambar.cloud"),
+		"api_key":  tftypes.NewValue(tftypes.String, ""),
+	})
+
+	if len(resp.Diagnostics.Errors()) != 1 || resp.Diagnostics.Errors()[0].Summary() != "Missing Ambar API key" {
+		t.Errorf("Configure returned %v, expected an empty api_key to be reported as missing", resp.Diagnostics)
+	}
+}
+
+func TestProviderConfigureProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AMBAR_ENDPOINT", "")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+	t.Setenv("AMBAR_PROFILE", "payments-prod")
+
+	if err := os.MkdirAll(filepath.Join(home, ".ambar"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	credentials := "[payments-prod]\nendpoint = euw1.api.ambar.cloud\napi_key = key-from-profile\n"
+	if err := os.WriteFile(filepath.Join(home, ".ambar", "credentials"), []byte(credentials), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := testProviderConfigure(t, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+
+	client := resp.ResourceData.(*ambarProviderData).Client.GetConfig()
+	if client.Host != "euw1.api.ambar.cloud" || client.DefaultHeader["x-api-key"] != "key-from-environment" {
+		t.Errorf("Configure used endpoint %q and key %q, expected the environment to take precedence over the profile", client.Host, client.DefaultHeader["x-api-key"])
+	}
+
+	resp = testProviderConfigure(t, map[string]tftypes.Value{
+		"profile": tftypes.NewValue(tftypes.String, "payments-staging"),
+	})
+	if len(resp.Diagnostics.Errors()) != 1 || resp.Diagnostics.Errors()[0].Summary() != "Unable to read Ambar profile" {
+		t.Errorf("Configure returned %v, expected the missing profile to be reported", resp.Diagnostics)
+	}
+}
+
+func TestProviderConfigureCredentialProcess(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "use1.api.ambar.cloud")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+
+	command := types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue("/bin/sh"),
+		types.StringValue("-c"),
+		types.StringValue(`echo "token refreshed" >&2; echo '{"api_key": "key-from-process", "endpoint": "euw1.api.ambar.cloud"}'`),
+	})
+	commandValue, err := command.ToTerraformValue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := testProviderConfigure(t, map[string]tftypes.Value{"credential_process": commandValue})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+	if len(resp.Diagnostics.Warnings()) != 1 || resp.Diagnostics.Warnings()[0].Detail() != "token refreshed" {
+		t.Errorf("Configure returned %v, expected a warning with the stderr of the credential process", resp.Diagnostics)
+	}
+
+	client := resp.ResourceData.(*ambarProviderData).Client.GetConfig()
+	if client.Host != "euw1.api.ambar.cloud" || client.DefaultHeader["x-api-key"] != "key-from-process" {
+		t.Errorf("Configure used endpoint %q and key %q, expected the credential process to take precedence over the environment", client.Host, client.DefaultHeader["x-api-key"])
+	}
+}
+
+func TestProviderConfigureRegion(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+	t.Setenv("AMBAR_REGION", "use1")
+
+	resp := testProviderConfigure(t, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+	if host := resp.ResourceData.(*ambarProviderData).Client.GetConfig().Host; host != "use1.api.ambar.cloud" {
+		t.Errorf("Configure used endpoint %q for AMBAR_REGION", host)
+	}
+
+	resp = testProviderConfigure(t, map[string]tftypes.Value{
+		"region": tftypes.NewValue(tftypes.String, "euw1"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+	if host := resp.ResourceData.(*ambarProviderData).Client.GetConfig().Host; host != "euw1.api.ambar.cloud" {
+		t.Errorf("Configure used endpoint %q, expected the region attribute to take precedence over AMBAR_REGION", host)
+	}
+
+	t.Setenv("AMBAR_REGION", "mars1")
+	resp = testProviderConfigure(t, nil)
+	if len(resp.Diagnostics.Errors()) != 1 || resp.Diagnostics.Errors()[0].Summary() != "Invalid Ambar region" {
+		t.Errorf("Configure returned %v, expected the unknown AMBAR_REGION to be reported", resp.Diagnostics)
+	}
+
+	for name, value := range map[string]tftypes.Value{
+		"endpoint": tftypes.NewValue(tftypes.String, "euw1.api.ambar.cloud"),
+		"region":   tftypes.NewValue(tftypes.String, "euw1"),
+	} {
+		resp = testProviderConfigure(t, map[string]tftypes.Value{name: value})
+		if resp.Diagnostics.HasError() {
+			t.Errorf("Configure returned errors with %s set: %v, expected the unknown AMBAR_REGION to be ignored", name, resp.Diagnostics)
+		}
+	}
+}
+
+func TestProviderConfigureEndpointURL(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+
+	var requestPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"resources": []}`))
+	}))
+	defer server.Close()
+
+	resp := testProviderConfigure(t, map[string]tftypes.Value{
+		"endpoint":             tftypes.NewValue(tftypes.String, server.URL+"/ambar/"),
+		"validate_credentials": tftypes.NewValue(tftypes.Bool, true),
+		"request_timeout":      tftypes.NewValue(tftypes.String, "10s"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+	if !strings.HasPrefix(requestPath, "/ambar/") {
+		t.Errorf("Configure sent a request to %q, expected the base path of the endpoint to be used", requestPath)
+	}
+
+	resp = testProviderConfigure(t, map[string]tftypes.Value{
+		"endpoint":             tftypes.NewValue(tftypes.String, server.URL),
+		"insecure_skip_verify": tftypes.NewValue(tftypes.Bool, true),
+		"request_timeout":      tftypes.NewValue(tftypes.String, "soon"),
+	})
+	if len(resp.Diagnostics.Warnings()) != 1 || len(resp.Diagnostics.Errors()) != 1 || resp.Diagnostics.Errors()[0].Summary() != "Invalid Ambar request timeout" {
+		t.Errorf("Configure returned %v, expected a warning for insecure_skip_verify and an invalid request_timeout", resp.Diagnostics)
+	}
+}
+
+func TestProviderConfigureReadOnly(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "region.api.ambar.cloud")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+	t.Setenv("AMBAR_READ_ONLY", "true")
+
+	resp := testProviderConfigure(t, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+	if !resp.ResourceData.(*ambarProviderData).ReadOnly {
+		t.Errorf("Configure did not enable read-only mode from AMBAR_READ_ONLY")
+	}
+
+	resp = testProviderConfigure(t, map[string]tftypes.Value{
+		"read_only": tftypes.NewValue(tftypes.Bool, false),
+	})
+	if resp.Diagnostics.HasError() || resp.ResourceData.(*ambarProviderData).ReadOnly {
+		t.Errorf("Configure returned %v, expected read_only to take precedence over AMBAR_READ_ONLY", resp.Diagnostics)
+	}
+
+	t.Setenv("AMBAR_READ_ONLY", "sometimes")
+	resp = testProviderConfigure(t, nil)
+	if len(resp.Diagnostics.Errors()) != 1 || resp.Diagnostics.Errors()[0].Summary() != "Invalid Ambar read-only mode" {
+		t.Errorf("Configure returned %v, expected the invalid AMBAR_READ_ONLY to be reported", resp.Diagnostics)
+	}
+}
+
+func TestProviderConfigureUnknown(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "region.api.ambar.cloud")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "")
+
+	values := map[string]tftypes.Value{
+		"api_key": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	}
+
+	resp := testProviderConfigureWithCapabilities(t, values, provider.ConfigureProviderClientCapabilities{DeferralAllowed: true})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+	if resp.Deferred == nil || resp.Deferred.Reason != provider.DeferredReasonProviderConfigUnknown {
+		t.Errorf("Configure returned %v, expected the resources to be deferred", resp.Deferred)
+	}
+
+	resp = testProviderConfigure(t, values)
+	if resp.Deferred != nil {
+		t.Errorf("Configure deferred for a Terraform client without deferral support")
+	}
+	if len(resp.Diagnostics.Errors()) != 1 || resp.Diagnostics.Errors()[0].Summary() != "Unknown Ambar API key" {
+		t.Errorf("Configure returned %v, expected the unknown api_key to be reported", resp.Diagnostics)
+	}
+
+	// Every setting which must be known is deferred, not only the credentials.
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+	for name, value := range map[string]tftypes.Value{
+		"proxy_url":            tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"insecure_skip_verify": tftypes.NewValue(tftypes.Bool, tftypes.UnknownValue),
+		"audit_log_path":       tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"read_only":            tftypes.NewValue(tftypes.Bool, tftypes.UnknownValue),
+		"ownership_tag":        tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	} {
+		resp = testProviderConfigureWithCapabilities(t, map[string]tftypes.Value{name: value}, provider.ConfigureProviderClientCapabilities{DeferralAllowed: true})
+		if resp.Diagnostics.HasError() || resp.Deferred == nil {
+			t.Errorf("Configure returned %v and deferral %v for an unknown %s, expected the resources to be deferred", resp.Diagnostics, resp.Deferred, name)
+		}
+	}
+
+	resp = testProviderConfigure(t, map[string]tftypes.Value{
+		"ownership_tag": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	})
+	if resp.Deferred != nil {
+		t.Errorf("Configure deferred for a Terraform client without deferral support")
+	}
+	if len(resp.Diagnostics.Errors()) != 1 || resp.Diagnostics.Errors()[0].Summary() != "Unknown Ambar ownership tag" {
+		t.Errorf("Configure returned %v, expected the unknown ownership_tag to be reported", resp.Diagnostics)
+	}
+}
+
+func TestProviderConfigureUserAgent(t *testing.T) {
+	t.Setenv("AMBAR_ENDPOINT", "region.api.ambar.cloud")
+	t.Setenv("AMBAR_ENVIRONMENT_KEY", "key-from-environment")
+	t.Setenv("TF_APPEND_USER_AGENT", "")
+
+	resp := testProviderConfigure(t, map[string]tftypes.Value{
+		"user_agent_suffix": tftypes.NewValue(tftypes.String, "nightly-drift"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure returned errors: %v", resp.Diagnostics)
+	}
+
+	if agent := resp.ResourceData.(*ambarProviderData).Client.GetConfig().UserAgent; agent != "terraform-provider-ambar/test nightly-drift" {
+		t.Errorf("Configure set the User-Agent %q", agent)
+	}
+}
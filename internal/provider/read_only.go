@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// In read-only mode, such as for drift detection pipelines using production keys, every Create, Update and Delete
+// fails before reaching Ambar, while reads, imports and data sources keep working.
+
+// checkReadOnly reports an error when the provider is in read-only mode, so a resource can not be changed. It returns
+// true when the operation must not continue.
+func checkReadOnly(diags *diag.Diagnostics, readOnly bool, operation string, resourceType string) bool {
+	if !readOnly {
+		return false
+	}
+
+	diags.AddError(
+		"Ambar provider is read-only",
+		fmt.Sprintf("Unable to %s %s, as the provider is configured with read_only or AMBAR_READ_ONLY. "+
+			"Disable read-only mode to make changes to Ambar resources.", operation, resourceType),
+	)
+	return true
+}
+
+// readOnlyTransport refuses to send requests which change Ambar resources, guarding against calls made outside of the
+// resource Create, Update and Delete methods.
+type readOnlyTransport struct {
+	next http.RoundTripper
+}
+
+// newReadOnlyTransport returns a transport refusing to send mutating requests through next.
+func newReadOnlyTransport(next http.RoundTripper) http.RoundTripper {
+	return &readOnlyTransport{next: next}
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if operation, ok := mutatingOperation(req); ok {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, errors.New("the Ambar provider is read-only, refusing to call " + operation.operation)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func TestReadOnlyResources(t *testing.T) {
+	ctx := context.Background()
+
+	resources := map[string]resource.Resource{
+		"ambar_data_source":      &dataSourceResource{readOnly: true},
+		"ambar_filter":           &FilterResource{readOnly: true},
+		"ambar_data_destination": &DataDestinationResource{readOnly: true},
+		"ambar_pipeline":         &pipelineResource{readOnly: true},
+	}
+
+	// The resources have no client, so reaching Ambar would panic.
+	for name, r := range resources {
+		var createResp resource.CreateResponse
+		r.Create(ctx, resource.CreateRequest{}, &createResp)
+
+		var updateResp resource.UpdateResponse
+		r.Update(ctx, resource.UpdateRequest{}, &updateResp)
+
+		var deleteResp resource.DeleteResponse
+		r.Delete(ctx, resource.DeleteRequest{}, &deleteResp)
+
+		for operation, diags := range map[string]int{
+			"create": len(createResp.Diagnostics.Errors()),
+			"update": len(updateResp.Diagnostics.Errors()),
+			"delete": len(deleteResp.Diagnostics.Errors()),
+		} {
+			if diags != 1 {
+				t.Errorf("%s: %s returned %d errors, expected the read-only error", name, operation, diags)
+			}
+		}
+	}
+}
+
+func TestReadOnlyTransport(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newReadOnlyTransport(http.DefaultTransport)}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		req, err := http.NewRequest(method, server.URL+"/api/source", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.Do(req)
+		if method == http.MethodGet {
+			if err != nil {
+				t.Errorf("read-only transport refused %s: %s", method, err)
+				continue
+			}
+			resp.Body.Close()
+		} else if err == nil {
+			resp.Body.Close()
+			t.Errorf("read-only transport sent %s", method)
+		}
+	}
+
+	if len(methods) != 1 || methods[0] != http.MethodGet {
+		t.Errorf("server received %v, expected only the GET request", methods)
+	}
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// ambarRegionEndpoints maps each Ambar region to the host of its API. Keys are region specific, so the provider region
+// attribute and the endpoint_for_region function save practitioners from looking up and pasting hostnames.
+var ambarRegionEndpoints = map[string]string{
+	"euw1": "euw1.api.ambar.cloud",
+	"use1": "use1.api.ambar.cloud",
+}
+
+// ambarRegions returns the known Ambar regions in alphabetical order.
+func ambarRegions() []string {
+	regions := make([]string, 0, len(ambarRegionEndpoints))
+	for region := range ambarRegionEndpoints {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &endpointForRegionFunction{}
+
+func NewEndpointForRegionFunction() function.Function {
+	return &endpointForRegionFunction{}
+}
+
+// endpointForRegionFunction returns the API endpoint of an Ambar region.
+type endpointForRegionFunction struct{}
+
+func (f *endpointForRegionFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "endpoint_for_region"
+}
+
+func (f *endpointForRegionFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Look up the Ambar API endpoint of a region",
+		MarkdownDescription: "Returns the Ambar API endpoint of a region, as used by the provider `endpoint` attribute. The region must be one of `" + strings.Join(ambarRegions(), "`, `") + "`.",
+		Description:         "Returns the Ambar API endpoint of a region, as used by the provider endpoint attribute. The region must be one of " + strings.Join(ambarRegions(), ", ") + ".",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "region",
+				MarkdownDescription: "The Ambar region, such as `euw1`.",
+				Description:         "The Ambar region, such as euw1.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *endpointForRegionFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var region string
+
+	resp.Error = req.Arguments.Get(ctx, &region)
+	if resp.Error != nil {
+		return
+	}
+
+	endpoint, ok := ambarRegionEndpoints[region]
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(0, "Unknown Ambar region "+region+", expected one of: "+strings.Join(ambarRegions(), ", "))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, endpoint)
+}
@@ -0,0 +1,21 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestEndpointForRegionFunction(t *testing.T) {
+	result, err := runFilterFunction(t, NewEndpointForRegionFunction(), types.StringValue("euw1"))
+	if err != nil {
+		t.Fatalf("endpoint_for_region returned unexpected error: %s", err)
+	}
+	if expected := types.StringValue("euw1.api.ambar.cloud"); !result.Equal(expected) {
+		t.Errorf("endpoint_for_region returned %s, expected %s", result, expected)
+	}
+
+	if _, err := runFilterFunction(t, NewEndpointForRegionFunction(), types.StringValue("mars1")); err == nil {
+		t.Errorf("endpoint_for_region expected an error for an unknown region")
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	Ambar "github.com/ambarltd/ambar_go_client"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strconv"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &unmanagedResourcesDataSource{}
+var _ datasource.DataSourceWithConfigure = &unmanagedResourcesDataSource{}
+
+func NewUnmanagedResourcesDataSource() datasource.DataSource {
+	return &unmanagedResourcesDataSource{}
+}
+
+// unmanagedResourcesDataSource lists the Ambar resources which were not created with a given ownership tag, to help
+// find resources created outside of Terraform, or left behind by a configuration which no longer manages them.
+type unmanagedResourcesDataSource struct {
+	client       *Ambar.APIClient
+	ownershipTag string
+}
+
+// unmanagedResourcesDataSourceModel describes the data source data model.
+type unmanagedResourcesDataSourceModel struct {
+	OwnershipTag types.String             `tfsdk:"ownership_tag"`
+	ResourceType types.String             `tfsdk:"resource_type"`
+	Resources    []unmanagedResourceModel `tfsdk:"resources"`
+}
+
+// unmanagedResourceModel describes an Ambar resource without the ownership tag.
+type unmanagedResourceModel struct {
+	ResourceType types.String `tfsdk:"resource_type"`
+	ResourceId   types.String `tfsdk:"resource_id"`
+	Description  types.String `tfsdk:"description"`
+	State        types.String `tfsdk:"state"`
+	OwnershipTag types.String `tfsdk:"ownership_tag"`
+}
+
+func (d *unmanagedResourcesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_unmanaged_resources"
+}
+
+func (d *unmanagedResourcesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Lists the Ambar resources in your environment whose description does not carry an ownership tag, such as resources created outside of Terraform or by a configuration without the provider's `ownership_tag` set.",
+		Description:         "Lists the Ambar resources in your environment whose description does not carry an ownership tag, such as resources created outside of Terraform or by a configuration without the provider's ownership_tag set.",
+
+		Attributes: map[string]schema.Attribute{
+			"ownership_tag": schema.StringAttribute{
+				MarkdownDescription: "The ownership tag resources are expected to carry. Defaults to the provider's `ownership_tag`. Resources tagged with a different tag are also listed, along with the tag they carry.",
+				Description:         "The ownership tag resources are expected to carry. Defaults to the provider's ownership_tag. Resources tagged with a different tag are also listed, along with the tag they carry.",
+				Optional:            true,
+			},
+			"resource_type": schema.StringAttribute{
+				MarkdownDescription: "Only list resources of this Ambar resource type, such as `DataSource`, `Filter` or `DataDestination`. Lists resources of every type when not set.",
+				Description:         "Only list resources of this Ambar resource type, such as DataSource, Filter or DataDestination. Lists resources of every type when not set.",
+				Optional:            true,
+			},
+			"resources": schema.ListNestedAttribute{
+				MarkdownDescription: "The Ambar resources without the ownership tag.",
+				Description:         "The Ambar resources without the ownership tag.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"resource_type": schema.StringAttribute{
+							MarkdownDescription: "The Ambar resource type.",
+							Description:         "The Ambar resource type.",
+							Computed:            true,
+						},
+						"resource_id": schema.StringAttribute{
+							MarkdownDescription: "The unique Ambar resource id.",
+							Description:         "The unique Ambar resource id.",
+							Computed:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "The description of the resource, including any ownership marker.",
+							Description:         "The description of the resource, including any ownership marker.",
+							Computed:            true,
+						},
+						"state": schema.StringAttribute{
+							MarkdownDescription: "The current state of the resource.",
+							Description:         "The current state of the resource.",
+							Computed:            true,
+						},
+						"ownership_tag": schema.StringAttribute{
+							MarkdownDescription: "The ownership tag the resource carries, when it was created with a different tag.",
+							Description:         "The ownership tag the resource carries, when it was created with a different tag.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *unmanagedResourcesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ambarProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ambarProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = providerData.Client
+	d.ownershipTag = providerData.OwnershipTag
+}
+
+func (d *unmanagedResourcesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data unmanagedResourcesDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ownershipTag := d.ownershipTag
+	if !data.OwnershipTag.IsNull() {
+		ownershipTag = data.OwnershipTag.ValueString()
+	}
+
+	if ownershipTag == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ownership_tag"),
+			"Missing Ambar ownership tag",
+			"Set ownership_tag on this data source or the provider, so resources without it can be listed.",
+		)
+		return
+	}
+
+	var listResources Ambar.ListResourcesRequest
+	listResources.ResourceType = data.ResourceType.ValueStringPointer()
+
+	data.Resources = []unmanagedResourceModel{}
+
+	for {
+		listResourcesResponse, _, err := d.client.AmbarAPI.ListResources(ctx).ListResourcesRequest(listResources).Execute()
+		if err != nil {
+			resp.Diagnostics.AddError("Unable to list Ambar resources.", err.Error())
+			return
+		}
+
+		for _, resourceTypeDetails := range listResourcesResponse.Resources {
+			if !data.ResourceType.IsNull() && !strings.EqualFold(resourceTypeDetails.GetResourceType(), data.ResourceType.ValueString()) {
+				continue
+			}
+
+			for _, details := range resourceTypeDetails.Details {
+				tag, tagged := ownershipTagOf(details.GetDescription())
+				if tagged && tag == ownershipTag {
+					continue
+				}
+
+				resource := unmanagedResourceModel{
+					ResourceType: types.StringPointerValue(resourceTypeDetails.ResourceType),
+					ResourceId:   types.StringPointerValue(details.ResourceId),
+					Description:  types.StringPointerValue(details.Description),
+					State:        types.StringPointerValue(details.State),
+					OwnershipTag: types.StringNull(),
+				}
+				if tagged {
+					resource.OwnershipTag = types.StringValue(tag)
+				}
+
+				data.Resources = append(data.Resources, resource)
+			}
+		}
+
+		if listResourcesResponse.NextPage == nil {
+			break
+		}
+
+		page := strconv.Itoa(int(*listResourcesResponse.NextPage))
+		listResources.Page = &page
+	}
+
+	tflog.Debug(ctx, "Found "+strconv.Itoa(len(data.Resources))+" Ambar resources without ownership tag "+ownershipTag)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"os"
+	"strings"
+)
+
+// userAgent returns the User-Agent the provider sends to Ambar, so Ambar support can tell which provider build and
+// Terraform version made a call. Teams can tag their pipelines with the user_agent_suffix attribute, or the
+// TF_APPEND_USER_AGENT environment variable used by other Terraform providers.
+func userAgent(providerVersion string, terraformVersion string, suffix string) string {
+	parts := []string{"terraform-provider-ambar/" + providerVersion}
+
+	if terraformVersion != "" {
+		parts = append(parts, "Terraform/"+terraformVersion)
+	}
+
+	if appended := strings.TrimSpace(os.Getenv("TF_APPEND_USER_AGENT")); appended != "" {
+		parts = append(parts, appended)
+	}
+
+	if suffix = strings.TrimSpace(suffix); suffix != "" {
+		parts = append(parts, suffix)
+	}
+
+	return strings.Join(parts, " ")
+}
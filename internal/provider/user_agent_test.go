@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	t.Setenv("TF_APPEND_USER_AGENT", "")
+
+	if agent := userAgent("1.2.0", "1.9.5", ""); agent != "terraform-provider-ambar/1.2.0 Terraform/1.9.5" {
+		t.Errorf("userAgent returned %q", agent)
+	}
+
+	if agent := userAgent("dev", "", " nightly-drift "); agent != "terraform-provider-ambar/dev nightly-drift" {
+		t.Errorf("userAgent returned %q", agent)
+	}
+
+	t.Setenv("TF_APPEND_USER_AGENT", "ci/github-actions")
+	if agent := userAgent("1.2.0", "1.9.5", "payments"); agent != "terraform-provider-ambar/1.2.0 Terraform/1.9.5 ci/github-actions payments" {
+		t.Errorf("userAgent returned %q", agent)
+	}
+}
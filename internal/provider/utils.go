@@ -1,6 +1,13 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net/http"
 	"regexp"
 	"strings"
 )
@@ -23,3 +30,89 @@ func AmbarApiErrorToTerraformErrorString(apiString string) string {
 	errorContent := strings.Trim(slicedString[len(slicedString)-1], "\"{}")
 	return toSnakeCase(errorContent)
 }
+
+// checkDependency reports an attribute error when an Ambar resource referenced by the planned resource could not be
+// found, or is in a state where it can no longer be used. Other errors describing the resource are only logged, as
+// they will be surfaced again during apply. It returns true when the referenced resource can be used.
+func checkDependency(ctx context.Context, diags *diag.Diagnostics, attributePath path.Path, resourceType string, resourceId string, state string, httpResponse *http.Response, err error) bool {
+	if err != nil {
+		if httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound {
+			diags.AddAttributeError(
+				attributePath,
+				resourceType+" not found",
+				fmt.Sprintf("The %s %s does not exist. It may have been deleted outside of Terraform, check the id or recreate the %s.", resourceType, resourceId, resourceType),
+			)
+			return false
+		}
+
+		tflog.Debug(ctx, "Unable to describe "+resourceType+" "+resourceId+" while planning: "+err.Error())
+		return false
+	}
+
+	switch state {
+	case "DELETING", "FAILED":
+		diags.AddAttributeError(
+			attributePath,
+			resourceType+" cannot be used",
+			fmt.Sprintf("The %s %s is in the %s state and can no longer be used. Replace the %s, or reference a different one.", resourceType, resourceId, state, resourceType),
+		)
+		return false
+	}
+
+	return true
+}
+
+// checkResourceGuards fails the plan when it would destroy or replace an Ambar resource which has prevent_replay or
+// deletion_protection enabled. replacedBy lists the attributes forcing the replacement, and is empty when the resource
+// is being destroyed. It returns true when the plan may go ahead.
+func checkResourceGuards(diags *diag.Diagnostics, resourceType string, resourceId string, replacedBy []string, preventReplay bool, deletionProtection bool) bool {
+	if len(replacedBy) == 0 {
+		if !deletionProtection {
+			return true
+		}
+
+		diags.AddAttributeError(
+			path.Root("deletion_protection"),
+			resourceType+" is protected from deletion",
+			fmt.Sprintf("The %s %s has deletion_protection enabled, so it cannot be destroyed. To proceed, set deletion_protection "+
+				"to false and apply that change on its own, then destroy the %s.", resourceType, resourceId, resourceType),
+		)
+		return false
+	}
+
+	changes := strings.Join(replacedBy, ", ")
+
+	if deletionProtection {
+		diags.AddAttributeError(
+			path.Root("deletion_protection"),
+			resourceType+" is protected from deletion",
+			fmt.Sprintf("Changes to %s require the %s %s to be replaced, which destroys it, but it has deletion_protection enabled. "+
+				"To proceed, revert the changes to %s, or set deletion_protection to false and apply that change on its own "+
+				"before applying the replacement.", changes, resourceType, resourceId, changes),
+		)
+		return false
+	}
+
+	if preventReplay {
+		diags.AddAttributeError(
+			path.Root("prevent_replay"),
+			resourceType+" replacement would replay records",
+			fmt.Sprintf("Changes to %s require the %s %s to be replaced, which resets message transport and replays records, but "+
+				"it has prevent_replay enabled. To proceed, revert the changes to %s, or set prevent_replay to false and apply "+
+				"that change on its own before applying the replacement.", changes, resourceType, resourceId, changes),
+		)
+		return false
+	}
+
+	return true
+}
+
+// containsUnknown reports whether any of the values is unknown.
+func containsUnknown(values []attr.Value) bool {
+	for _, value := range values {
+		if value.IsUnknown() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestCheckDependency(t *testing.T) {
+	tests := []struct {
+		name         string
+		state        string
+		httpResponse *http.Response
+		err          error
+		usable       bool
+		errors       int
+	}{
+		{"ready", "READY", nil, nil, true, 0},
+		{"creating", "CREATING", nil, nil, true, 0},
+		{"failed", "FAILED", nil, nil, false, 1},
+		{"deleting", "DELETING", nil, nil, false, 1},
+		{"not found", "", &http.Response{StatusCode: http.StatusNotFound}, errors.New("404 Not Found"), false, 1},
+		{"unavailable", "", &http.Response{StatusCode: http.StatusServiceUnavailable}, errors.New("503 Service Unavailable"), false, 0},
+	}
+
+	for _, test := range tests {
+		var diags diag.Diagnostics
+		usable := checkDependency(context.Background(), &diags, path.Root("filter_ids").AtListIndex(1), "Filter", "AMBAR-1234567890", test.state, test.httpResponse, test.err)
+
+		if usable != test.usable {
+			t.Errorf("%s: checkDependency returned %t, expected %t", test.name, usable, test.usable)
+		}
+
+		if diags.ErrorsCount() != test.errors {
+			t.Errorf("%s: checkDependency reported %d errors, expected %d", test.name, diags.ErrorsCount(), test.errors)
+		}
+
+		for _, d := range diags.Errors() {
+			withPath, ok := d.(diag.DiagnosticWithPath)
+			if !ok || !withPath.Path().Equal(path.Root("filter_ids").AtListIndex(1)) {
+				t.Errorf("%s: checkDependency reported an error without the attribute path", test.name)
+			}
+		}
+	}
+}
+
+func TestCheckResourceGuards(t *testing.T) {
+	tests := []struct {
+		name               string
+		replacedBy         []string
+		preventReplay      bool
+		deletionProtection bool
+		allowed            bool
+	}{
+		{"destroy", nil, false, false, true},
+		{"destroy with prevent_replay", nil, true, false, true},
+		{"destroy with deletion_protection", nil, false, true, false},
+		{"replace", []string{"description"}, false, false, true},
+		{"replace with prevent_replay", []string{"description"}, true, false, false},
+		{"replace with deletion_protection", []string{"description"}, false, true, false},
+	}
+
+	for _, test := range tests {
+		var diags diag.Diagnostics
+		allowed := checkResourceGuards(&diags, "DataSource", "AMBAR-1234567890", test.replacedBy, test.preventReplay, test.deletionProtection)
+
+		if allowed != test.allowed {
+			t.Errorf("%s: checkResourceGuards returned %t, expected %t", test.name, allowed, test.allowed)
+		}
+
+		if diags.HasError() == allowed {
+			t.Errorf("%s: checkResourceGuards reported errors %v, expected none when allowed", test.name, diags)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	Ambar "github.com/ambarltd/ambar_go_client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// A wrong key or a key for another region otherwise only shows up as a confusing error from the first resource call,
+// so by default the provider checks its credentials with one cheap authenticated call when it is configured.
+
+// credentialValidationTimeout bounds how long the provider waits for the credential check.
+const credentialValidationTimeout = 30 * time.Second
+
+// validateCredentials lists the first page of resources using client, adding an error to diags explaining why the
+// endpoint or key can not be used. It returns false when the credentials are not usable.
+func validateCredentials(ctx context.Context, client *Ambar.APIClient, diags *diag.Diagnostics, endpoint string) bool {
+	ctx, cancel := context.WithTimeout(ctx, credentialValidationTimeout)
+	defer cancel()
+
+	tflog.Debug(ctx, "Validating Ambar credentials")
+	_, httpResponse, err := client.AmbarAPI.ListResources(ctx).ListResourcesRequest(Ambar.ListResourcesRequest{}).Execute()
+	if err == nil {
+		return true
+	}
+
+	const disable = " Set validate_credentials to false to skip this check."
+
+	switch {
+	case httpResponse == nil:
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"Ambar endpoint unreachable",
+			"The provider could not reach the Ambar API at "+strconv.Quote(endpoint)+": "+err.Error()+". "+
+				"Check the endpoint or region, and your network connection."+disable,
+		)
+	case httpResponse.StatusCode == http.StatusUnauthorized:
+		diags.AddAttributeError(
+			path.Root("api_key"),
+			"Invalid Ambar API key",
+			"The Ambar API at "+strconv.Quote(endpoint)+" did not accept the API key. "+
+				"Check that the key is correct and has not been deleted."+disable,
+		)
+	case httpResponse.StatusCode == http.StatusForbidden:
+		if region, ok := keyRegion(ctx, client); ok {
+			diags.AddAttributeError(
+				path.Root("api_key"),
+				"Ambar API key belongs to a different region",
+				"The Ambar API at "+strconv.Quote(endpoint)+" refused the API key, which belongs to the "+strconv.Quote(region)+" region. "+
+					"Keys are region specific, so set region to "+strconv.Quote(region)+" or use a key created in this region."+disable,
+			)
+			break
+		}
+
+		diags.AddAttributeError(
+			path.Root("api_key"),
+			"Ambar API key refused",
+			"The Ambar API at "+strconv.Quote(endpoint)+" refused the API key. Check that the key has access to this environment. "+
+				"The key was not accepted by any other Ambar region either, which is only checked when the endpoint is one of the regional Ambar endpoints."+disable,
+		)
+	default:
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"Unable to validate Ambar credentials",
+			"The Ambar API at "+strconv.Quote(endpoint)+" returned an unexpected response: "+httpResponse.Status+"."+disable,
+		)
+	}
+
+	return false
+}
+
+// keyRegion returns the region whose Ambar API accepts the key of client, when client uses another regional endpoint.
+// The key is never sent to a region when client uses a custom endpoint, such as a proxy or a mock of the Ambar API.
+func keyRegion(ctx context.Context, client *Ambar.APIClient) (string, bool) {
+	cfg := client.GetConfig()
+
+	regional := false
+	for _, host := range ambarRegionEndpoints {
+		regional = regional || host == cfg.Host
+	}
+	if !regional {
+		return "", false
+	}
+
+	for _, region := range ambarRegions() {
+		host := ambarRegionEndpoints[region]
+		if host == cfg.Host {
+			continue
+		}
+
+		regionCfg := Ambar.NewConfiguration()
+		regionCfg.DefaultHeader = cfg.DefaultHeader
+		regionCfg.UserAgent = cfg.UserAgent
+		regionCfg.HTTPClient = cfg.HTTPClient
+		regionCfg.Scheme = "https"
+		regionCfg.Host = host
+		regionCfg.Servers = Ambar.ServerConfigurations{{URL: "https://" + host}}
+
+		tflog.Debug(ctx, "Checking whether the Ambar API key belongs to the "+region+" region")
+		_, _, err := Ambar.NewAPIClient(regionCfg).AmbarAPI.ListResources(ctx).ListResourcesRequest(Ambar.ListResourcesRequest{}).Execute()
+		if err == nil {
+			return region, true
+		}
+	}
+
+	return "", false
+}
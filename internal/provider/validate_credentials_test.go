@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	Ambar "github.com/ambarltd/ambar_go_client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// testAmbarClient returns a client for the Ambar API served by server.
+func testAmbarClient(server *httptest.Server) *Ambar.APIClient {
+	cfg := Ambar.NewConfiguration()
+	cfg.Scheme = "http"
+	cfg.Host = strings.TrimPrefix(server.URL, "http://")
+	cfg.AddDefaultHeader("x-api-key", "test-key")
+	return Ambar.NewAPIClient(cfg)
+}
+
+func TestValidateCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		summary string
+	}{
+		{"valid", http.StatusOK, ""},
+		{"invalid key", http.StatusUnauthorized, "Invalid Ambar API key"},
+		{"refused key", http.StatusForbidden, "Ambar API key refused"},
+		{"unexpected", http.StatusNotFound, "Unable to validate Ambar credentials"},
+		{"server error", http.StatusServiceUnavailable, "Unable to validate Ambar credentials"},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("x-api-key") != "test-key" {
+				t.Errorf("%s: request was sent without the API key", test.name)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(test.status)
+			_, _ = w.Write([]byte(`{"resources": []}`))
+		}))
+
+		var diags diag.Diagnostics
+		valid := validateCredentials(context.Background(), testAmbarClient(server), &diags, server.URL)
+		server.Close()
+
+		if valid != (test.summary == "") {
+			t.Errorf("%s: validateCredentials returned %t", test.name, valid)
+		}
+		if test.summary != "" && (len(diags.Errors()) != 1 || diags.Errors()[0].Summary() != test.summary) {
+			t.Errorf("%s: validateCredentials returned %v, expected %q", test.name, diags, test.summary)
+		}
+	}
+}
+
+func TestValidateCredentialsUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	client := testAmbarClient(server)
+	server.Close()
+
+	var diags diag.Diagnostics
+	if validateCredentials(context.Background(), client, &diags, server.URL) {
+		t.Fatalf("validateCredentials expected the closed server to be unreachable")
+	}
+	if len(diags.Errors()) != 1 || diags.Errors()[0].Summary() != "Ambar endpoint unreachable" || !diags.Errors()[0].(diag.DiagnosticWithPath).Path().Equal(path.Root("endpoint")) {
+		t.Errorf("validateCredentials returned %v", diags)
+	}
+}
+
+func TestValidateCredentialsDifferentRegion(t *testing.T) {
+	// Each region is served by its own server, accepting the key only in use1.
+	servers := make(map[string]*httptest.Server)
+	for _, region := range []string{"euw1", "use1"} {
+		status := http.StatusForbidden
+		if region == "use1" {
+			status = http.StatusOK
+		}
+		servers[region] = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"resources": []}`))
+		}))
+		defer servers[region].Close()
+	}
+
+	previousRegionEndpoints := ambarRegionEndpoints
+	t.Cleanup(func() { ambarRegionEndpoints = previousRegionEndpoints })
+	ambarRegionEndpoints = map[string]string{}
+	for region, server := range servers {
+		ambarRegionEndpoints[region] = strings.TrimPrefix(server.URL, "https://")
+	}
+
+	regionClient := func(host string) *Ambar.APIClient {
+		cfg := Ambar.NewConfiguration()
+		cfg.Scheme = "https"
+		cfg.Host = host
+		cfg.AddDefaultHeader("x-api-key", "test-key")
+		// #nosec G402 -- the test servers use self-signed certificates.
+		cfg.HTTPClient = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+		return Ambar.NewAPIClient(cfg)
+	}
+
+	var diags diag.Diagnostics
+	if validateCredentials(context.Background(), regionClient(ambarRegionEndpoints["euw1"]), &diags, "euw1") {
+		t.Fatal("validateCredentials expected the key to be refused")
+	}
+	if len(diags.Errors()) != 1 || diags.Errors()[0].Summary() != "Ambar API key belongs to a different region" || !strings.Contains(diags.Errors()[0].Detail(), `"use1"`) {
+		t.Errorf("validateCredentials returned %v, expected the key to be found in use1", diags)
+	}
+
+	// The key is never sent to the regions for a custom endpoint.
+	custom := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer custom.Close()
+
+	diags = nil
+	validateCredentials(context.Background(), regionClient(strings.TrimPrefix(custom.URL, "https://")), &diags, custom.URL)
+	if len(diags.Errors()) != 1 || diags.Errors()[0].Summary() != "Ambar API key refused" {
+		t.Errorf("validateCredentials returned %v, expected the key to be refused", diags)
+	}
+}